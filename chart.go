@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tebeka/toggl/client"
+)
+
+const (
+	// chartWidth is the width of the longest bar
+	chartWidth = 40
+	// heatmapWeeks is the default number of weeks in heatmap
+	heatmapWeeks = 12
+)
+
+var (
+	// Partial blocks, index is eighths of a cell
+	barEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+	// Heatmap cells from no time to most time
+	heatUTF8  = []string{"·", "░", "▒", "▓", "█"}
+	heatASCII = []string{".", "-", "+", "*", "#"}
+)
+
+// isUTF8 returns true if the locale environment says the terminal is UTF-8
+func isUTF8() bool {
+	// First one set wins, same as setlocale
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+
+	return false
+}
+
+// chartRow is a labeled bar in a chart
+type chartRow struct {
+	label    string
+	duration time.Duration
+}
+
+// bar returns a horizontal bar width cells long
+func bar(width float64, utf bool) string {
+	if !utf {
+		return strings.Repeat("#", int(math.Round(width)))
+	}
+
+	eighths := int(math.Round(width * 8))
+	return strings.Repeat("█", eighths/8) + barEighths[eighths%8]
+}
+
+// printChart prints rows as horizontal bars, the longest is chartWidth wide
+func printChart(w io.Writer, rows []chartRow, utf bool) error {
+	var longest time.Duration
+	labelWidth := 0
+	for _, r := range rows {
+		longest = max(longest, r.duration)
+		labelWidth = max(labelWidth, utf8.RuneCountInString(r.label))
+	}
+
+	for _, r := range rows {
+		width := 0.0
+		if longest > 0 {
+			width = float64(r.duration) / float64(longest) * chartWidth
+		}
+
+		b := bar(width, utf)
+		pad := strings.Repeat(" ", labelWidth-utf8.RuneCountInString(r.label))
+		barPad := strings.Repeat(" ", chartWidth-utf8.RuneCountInString(b))
+		if _, err := fmt.Fprintf(w, "%s%s  %s%s  %s\n", r.label, pad, b, barPad, duration2hm(r.duration)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dailyTotals returns time per day (2006-01-02 in loc), entries crossing midnight are split
+func dailyTotals(entries []client.TimeEntry, loc *time.Location) map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	for _, e := range entries {
+		start := e.Start.In(loc)
+		end := start.Add(e.Elapsed())
+		for start.Before(end) {
+			day := startOfDay(start)
+			next := day.AddDate(0, 0, 1)
+			if end.Before(next) {
+				next = end
+			}
+			totals[day.Format("2006-01-02")] += next.Sub(start)
+			start = next
+		}
+	}
+
+	return totals
+}
+
+// heatLevel returns the heatmap cell level (0 is no time) of d relative to the busiest day
+func heatLevel(d, busiest time.Duration, levels int) int {
+	if d <= 0 || busiest <= 0 {
+		return 0
+	}
+
+	level := int(math.Ceil(float64(d) / float64(busiest) * float64(levels-1)))
+	return min(max(level, 1), levels-1)
+}
+
+// printHeatmap prints a calendar of time per day between start and end (inclusive days).
+// Rows are week days (starting at weekStart) and columns are weeks.
+func printHeatmap(w io.Writer, totals map[string]time.Duration, start, end time.Time, weekStart time.Weekday, utf bool) error {
+	cells := heatASCII
+	if utf {
+		cells = heatUTF8
+	}
+
+	var busiest, total time.Duration
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		d := totals[day.Format("2006-01-02")]
+		busiest = max(busiest, d)
+		total += d
+	}
+
+	first := start.AddDate(0, 0, -(int(start.Weekday())-int(weekStart)+7)%7)
+	weeks := 0
+	for day := first; !day.After(end); day = day.AddDate(0, 0, 7) {
+		weeks++
+	}
+
+	// Month of a week column is the month of its first day in range
+	month := func(i int) time.Month {
+		week := first.AddDate(0, 0, 7*i)
+		if week.Before(start) {
+			return start.Month()
+		}
+		return week.Month()
+	}
+
+	// Month names above the first week of the month, if there's room
+	const labelWidth = 4
+	header := []rune(strings.Repeat(" ", labelWidth+weeks*2))
+	free := 0
+	for i := range weeks {
+		if i > 0 && month(i) == month(i-1) {
+			continue
+		}
+
+		pos := labelWidth + i*2
+		name := month(i).String()[:3]
+		if pos < free || pos+len(name) > len(header) {
+			continue
+		}
+		copy(header[pos:], []rune(name))
+		free = pos + len(name) + 1
+	}
+
+	if _, err := fmt.Fprintln(w, strings.TrimRight(string(header), " ")); err != nil {
+		return err
+	}
+
+	for wd := range 7 {
+		var b strings.Builder
+		b.WriteString(first.AddDate(0, 0, wd).Format("Mon "))
+		for i := range weeks {
+			day := first.AddDate(0, 0, 7*i+wd)
+			cell := " "
+			if !day.Before(start) && !day.After(end) {
+				cell = cells[heatLevel(totals[day.Format("2006-01-02")], busiest, len(cells))]
+			}
+			b.WriteString(cell + " ")
+		}
+
+		if _, err := fmt.Fprintln(w, strings.TrimRight(b.String(), " ")); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nLess %s More  (total %s, busiest day %s)\n", strings.Join(cells, ""), duration2hm(total), duration2hm(busiest))
+	return err
+}
+
+// dayRecord is the machine readable output of a heatmap day
+type dayRecord struct {
+	Date     string  `json:"date"`
+	Duration seconds `json:"duration"`
+}
+
+func heatmapCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	until := fs.String("until", "", "end date, inclusive (default today)")
+	periods := make(map[string]*bool)
+	for _, rp := range reportPeriods {
+		periods[rp.name] = fs.Bool(rp.name, false, rp.desc)
+	}
+	registerOutput(fs)
+	simpleHelp(fs, "heatmap [flags] [since]", fmt.Sprintf("Show calendar of hours per day (since defaults to %d weeks ago).", heatmapWeeks))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 1 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	p, err := loadTimeParser()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, rp := range reportPeriods {
+		if *periods[rp.name] {
+			names = append(names, rp.name)
+		}
+	}
+
+	since := fs.Arg(0)
+	if since == "" && len(names) == 0 {
+		weeksAgo := p.startOfWeek(p.now).AddDate(0, 0, -7*(heatmapWeeks-1))
+		since = weeksAgo.Format("2006-01-02")
+	}
+
+	start, end, err := reportRange(p, since, *until, names)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	// Entries starting a day before might run into our range
+	entries, err := c.TimeEntries(ctx, start.AddDate(0, 0, -1), end.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	loc := p.now.Location()
+	totals := dailyTotals(entries, loc)
+
+	var records []dayRecord
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		records = append(records, dayRecord{date, seconds(totals[date])})
+	}
+
+	return writeRecords(os.Stdout, records, func() error {
+		return printHeatmap(os.Stdout, totals, start, end, p.weekStart, isUTF8())
+	})
+}
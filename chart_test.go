@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tebeka/toggl/client"
+)
+
+func Test_isUTF8(t *testing.T) {
+	cases := []struct {
+		all, ctype, lang string
+		expected         bool
+	}{
+		{"", "", "en_US.UTF-8", true},
+		{"", "", "en_US.utf8", true},
+		{"C", "", "en_US.UTF-8", false},
+		{"", "en_US.UTF-8", "C", true},
+		{"", "", "", false},
+	}
+
+	for _, tc := range cases {
+		t.Setenv("LC_ALL", tc.all)
+		t.Setenv("LC_CTYPE", tc.ctype)
+		t.Setenv("LANG", tc.lang)
+		if got := isUTF8(); got != tc.expected {
+			t.Errorf("%+v: expected %v, got %v", tc, tc.expected, got)
+		}
+	}
+}
+
+func Test_bar(t *testing.T) {
+	cases := []struct {
+		width    float64
+		utf      bool
+		expected string
+	}{
+		{3, true, "███"},
+		{2.5, true, "██▌"},
+		{0.125, true, "▏"},
+		{0, true, ""},
+		{2.5, false, "###"},
+		{2.4, false, "##"},
+	}
+
+	for _, tc := range cases {
+		if got := bar(tc.width, tc.utf); got != tc.expected {
+			t.Errorf("bar(%v, %v): expected %q, got %q", tc.width, tc.utf, tc.expected, got)
+		}
+	}
+}
+
+func Test_printChart(t *testing.T) {
+	rows := []chartRow{
+		{"api", 2 * time.Hour},
+		{"website", time.Hour},
+	}
+
+	var buf bytes.Buffer
+	if err := printChart(&buf, rows, false); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+
+	expected := "api      " + strings.Repeat("#", chartWidth) + "  02:00"
+	if lines[0] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[0])
+	}
+
+	expected = "website  " + strings.Repeat("#", chartWidth/2) + strings.Repeat(" ", chartWidth/2) + "  01:00"
+	if lines[1] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[1])
+	}
+}
+
+func Test_dailyTotals(t *testing.T) {
+	loc := time.FixedZone("IST", 2*60*60)
+	start := time.Date(2023, 1, 2, 21, 0, 0, 0, time.UTC) // 23:00 in IST
+	stop := start.Add(3 * time.Hour)
+	entries := []client.TimeEntry{
+		{Start: start, Stop: &stop},
+		{Start: start.Add(-10 * time.Hour), Duration: 1800},
+	}
+
+	totals := dailyTotals(entries, loc)
+	expected := map[string]time.Duration{
+		"2023-01-02": time.Hour + 30*time.Minute,
+		"2023-01-03": 2 * time.Hour,
+	}
+
+	if len(totals) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, totals)
+	}
+
+	for day, d := range expected {
+		if totals[day] != d {
+			t.Errorf("%s: expected %v, got %v", day, d, totals[day])
+		}
+	}
+}
+
+func Test_heatLevel(t *testing.T) {
+	cases := []struct {
+		d        time.Duration
+		expected int
+	}{
+		{0, 0},
+		{time.Minute, 1},
+		{time.Hour, 1},
+		{2 * time.Hour, 2},
+		{3 * time.Hour, 3},
+		{4 * time.Hour, 4},
+	}
+
+	for _, tc := range cases {
+		if got := heatLevel(tc.d, 4*time.Hour, 5); got != tc.expected {
+			t.Errorf("%v: expected %d, got %d", tc.d, tc.expected, got)
+		}
+	}
+}
+
+func Test_printHeatmap(t *testing.T) {
+	// Wednesday to Tuesday
+	start := time.Date(2023, 1, 25, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 6)
+	totals := map[string]time.Duration{
+		"2023-01-25": 4 * time.Hour,
+		"2023-01-30": time.Hour,
+		"2023-02-01": 8 * time.Hour, // out of range
+	}
+
+	var buf bytes.Buffer
+	if err := printHeatmap(&buf, totals, start, end, time.Monday, false); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `    Jan
+Mon   -
+Tue   .
+Wed #
+Thu .
+Fri .
+Sat .
+Sun .
+
+Less .-+*# More  (total 05:00, busiest day 04:00)
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -9,18 +9,44 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
 const (
-	// baseURL is the base rest API URL
-	baseURL = "https://api.track.toggl.com/api/v9"
+	// DefaultBaseURL is the base rest API URL
+	DefaultBaseURL = "https://api.track.toggl.com/api/v9"
+	// DefaultReportsURL is the base reports API URL
+	DefaultReportsURL = "https://api.track.toggl.com/reports/api/v3"
+
+	createdWith = "github.com/tebeka/toggl"
 )
 
 type Config struct {
 	APIToken    string
 	WorkspaceID int
 	Timeout     time.Duration
+
+	// BaseURL overrides DefaultBaseURL (e.g. for a proxy or a local server)
+	BaseURL string
+	// ReportsURL overrides DefaultReportsURL
+	ReportsURL string
+	// HTTPClient is used to make requests, a default http.Client if nil
+	HTTPClient *http.Client
+
+	// MaxRetries is the number of times a failed call is retried (0 = no retries)
+	MaxRetries int
+	// RetryWait is the initial backoff wait, doubled on every retry (default 500ms)
+	RetryWait time.Duration
+	// MaxRetryWait caps the backoff wait (default 30s)
+	MaxRetryWait time.Duration
+	// RetryAll retries non idempotent methods (POST, PATCH) on server errors as well
+	RetryAll bool
+
+	// RateLimit is the maximal number of requests per second (0 = no limit)
+	RateLimit float64
+	// RateBurst is the number of requests allowed in a burst (default 1)
+	RateBurst int
 }
 
 func (c Config) Validate() error {
@@ -36,37 +62,144 @@ func (c Config) Validate() error {
 		return fmt.Errorf("invalid timeout %v", c.Timeout)
 	}
 
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("invalid max retries %d", c.MaxRetries)
+	}
+
+	if c.RetryWait < 0 || c.MaxRetryWait < 0 {
+		return fmt.Errorf("invalid retry wait %v/%v", c.RetryWait, c.MaxRetryWait)
+	}
+
+	if c.RateLimit < 0 || c.RateBurst < 0 {
+		return fmt.Errorf("invalid rate limit %v/%d", c.RateLimit, c.RateBurst)
+	}
+
+	for _, u := range []string{c.BaseURL, c.ReportsURL} {
+		if u == "" {
+			continue
+		}
+
+		if err := validateURL(u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("bad URL %q: %w", s, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("bad URL %q: should be http(s)://host[/path]", s)
+	}
+
 	return nil
 }
 
 type Client struct {
-	cfg Config
-	c   http.Client
+	cfg        Config
+	c          *http.Client
+	baseURL    string
+	reportsURL string
+	limiter    *limiter
 }
 
 func New(cfg Config) (*Client, error) {
 	c := &Client{
-		cfg: cfg,
+		cfg:        cfg,
+		c:          cfg.HTTPClient,
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		reportsURL: strings.TrimSuffix(cfg.ReportsURL, "/"),
+	}
+
+	if c.c == nil {
+		c.c = &http.Client{}
+	}
+
+	if c.baseURL == "" {
+		c.baseURL = DefaultBaseURL
+	}
+
+	if c.reportsURL == "" {
+		c.reportsURL = DefaultReportsURL
+	}
+
+	if cfg.RateLimit > 0 {
+		c.limiter = newLimiter(cfg.RateLimit, cfg.RateBurst)
 	}
 
 	return c, nil
 }
 
-// call makes an API call with right credentials
-func (c *Client) call(method, url string, body io.Reader, out interface{}) error {
-	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
-	defer cancel()
+// call makes an API call with right credentials, retrying failed calls
+// according to the retry policy in the configuration.
+func (c *Client) call(ctx context.Context, method, url string, body io.Reader, out interface{}) error {
+	_, err := c.send(ctx, method, url, body, out, idempotent(method))
+	return err
+}
+
+// report makes a reports API call. Reports are read only POSTs, so they are
+// retried like idempotent calls.
+func (c *Client) report(ctx context.Context, url string, body io.Reader, out interface{}) (http.Header, error) {
+	return c.send(ctx, http.MethodPost, url, body, out, true)
+}
+
+// send is like call, but also returns the response header.
+// Safe calls are retried on server errors (see shouldRetry).
+func (c *Client) send(ctx context.Context, method, url string, body io.Reader, out interface{}, safe bool) (http.Header, error) {
+	// Read the body so we can send it again on retry
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = io.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		hdr, err := c.do(ctx, method, url, data, out)
+		if err == nil || attempt >= c.cfg.MaxRetries || !c.shouldRetry(ctx, safe, err) {
+			return hdr, err
+		}
+
+		if err := sleep(ctx, c.backoff(attempt, err)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// do makes a single API call.
+// The configured timeout is used only if ctx has no deadline.
+func (c *Client) do(ctx context.Context, method, url string, data []byte, out interface{}) (http.Header, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req.SetBasicAuth(c.cfg.APIToken, "api_token")
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.c.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer func() {
@@ -76,23 +209,64 @@ func (c *Client) call(method, url string, body io.Reader, out interface{}) error
 	}()
 
 	if resp.StatusCode >= http.StatusBadRequest {
-		return fmt.Errorf("%q: %s", url, resp.Status)
+		return nil, newAPIError(req, resp)
 	}
 
 	if out == nil {
-		return nil
+		return resp.Header, nil
 	}
 
 	dec := json.NewDecoder(resp.Body)
-	return dec.Decode(out)
+	return resp.Header, dec.Decode(out)
+}
+
+// jsonBody returns v encoded as JSON request body
+func jsonBody(v any) (io.Reader, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return &buf, nil
 }
 
 // Project is toggl project
 type Project struct {
-	Name       string `json:"name"`
-	ID         int    `json:"id"`
-	ClientID   int    `json:"cid"`
-	ClientName string
+	Name        string `json:"name"`
+	ID          int    `json:"id"`
+	ClientID    int    `json:"cid"`
+	ClientName  string
+	WorkspaceID int  `json:"wid"`
+	Active      bool `json:"active"`
+	Billable    bool `json:"billable"`
+	// Color is hex color (e.g. "#06aaf5")
+	Color          string `json:"color"`
+	ActualHours    int    `json:"actual_hours"`
+	EstimatedHours int    `json:"estimated_hours"`
+}
+
+func (p *Project) UnmarshalJSON(data []byte) error {
+	type project Project // Without UnmarshalJSON
+	aux := struct {
+		*project
+		// Older API has color index in color and hex in hex_color
+		HexColor string `json:"hex_color"`
+	}{project: (*project)(p)}
+
+	// Projects are active unless told otherwise
+	p.Active = true
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	switch {
+	case aux.HexColor != "":
+		p.Color = aux.HexColor
+	case !strings.HasPrefix(p.Color, "#"):
+		p.Color = ""
+	}
+
+	return nil
 }
 
 func (p Project) FullName() string {
@@ -102,14 +276,16 @@ func (p Project) FullName() string {
 	return p.Name
 }
 
-func (c *Client) Projects() ([]Project, error) {
-	url := fmt.Sprintf("%s/me/projects", baseURL)
+// Projects returns all projects, archived ones included (see Project.Active)
+func (c *Client) Projects(ctx context.Context) ([]Project, error) {
+	// The API returns only active projects by default
+	url := fmt.Sprintf("%s/me/projects?include_archived=true", c.baseURL)
 	var prjs []Project
-	if err := c.call(http.MethodGet, url, nil, &prjs); err != nil {
+	if err := c.call(ctx, http.MethodGet, url, nil, &prjs); err != nil {
 		return nil, err
 	}
 
-	clients, err := c.Clients()
+	clients, err := c.Clients(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -124,15 +300,15 @@ func (c *Client) Projects() ([]Project, error) {
 	return prjs, nil
 }
 
-func (c *Client) Clients() (map[int]string, error) {
-	url := fmt.Sprintf("%s/me/clients", baseURL)
+func (c *Client) Clients(ctx context.Context) (map[int]string, error) {
+	url := fmt.Sprintf("%s/me/clients", c.baseURL)
 
 	var cs []struct {
 		Name string `json:"name"`
 		ID   int    `json:"id"`
 	}
 
-	if err := c.call(http.MethodGet, url, nil, &cs); err != nil {
+	if err := c.call(ctx, http.MethodGet, url, nil, &cs); err != nil {
 		return nil, err
 	}
 
@@ -145,16 +321,20 @@ func (c *Client) Clients() (map[int]string, error) {
 
 // Timer is a toggle running timer
 type Timer struct {
-	ID      int       `json:"id"`
-	Project int       `json:"pid"`
-	Start   time.Time `json:"start"`
+	ID          int       `json:"id"`
+	Project     int       `json:"pid"`
+	Task        int       `json:"tid"`
+	Start       time.Time `json:"start"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	Billable    bool      `json:"billable"`
 }
 
-func (c *Client) Timer() (*Timer, error) {
-	url := fmt.Sprintf("%s/me/time_entries/current", baseURL)
+func (c *Client) Timer(ctx context.Context) (*Timer, error) {
+	url := fmt.Sprintf("%s/me/time_entries/current", c.baseURL)
 	var t Timer
 
-	if err := c.call(http.MethodGet, url, nil, &t); err != nil {
+	if err := c.call(ctx, http.MethodGet, url, nil, &t); err != nil {
 		return nil, err
 	}
 
@@ -166,74 +346,73 @@ func (c *Client) Timer() (*Timer, error) {
 }
 
 func (c *Client) timesURL() string {
-	return fmt.Sprintf("%s/workspaces/%d/time_entries", baseURL, c.cfg.WorkspaceID)
+	return fmt.Sprintf("%s/workspaces/%d/time_entries", c.baseURL, c.cfg.WorkspaceID)
 }
 
-func (c *Client) Start(pid int, start time.Time) error {
+// StartOptions are optional fields for a started time entry
+type StartOptions struct {
+	Description string
+	Tags        []string
+	// TaskID is 0 for no task
+	TaskID int
+	// Billable overrides the project default if not nil
+	Billable *bool
+}
+
+// Start starts a timer for project pid at start
+func (c *Client) Start(ctx context.Context, pid int, start time.Time, opts StartOptions) (*TimeEntry, error) {
 	data := map[string]any{
-		"created_with": "github.com/tebeka/toggl",
+		"created_with": createdWith,
 		"duration":     -1,
 		"project_id":   pid,
-		"start":        start.Format("2006-01-02T15:04:05Z"),
+		"start":        start.UTC().Format("2006-01-02T15:04:05Z"),
 		"workspace_id": c.cfg.WorkspaceID,
 	}
-	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
-	if err := enc.Encode(data); err != nil {
-		return err
+
+	if pid == 0 {
+		// No project
+		data["project_id"] = nil
 	}
-	return c.call(http.MethodPost, c.timesURL(), &buf, nil)
-}
 
-func (c *Client) Stop(id int) (int, time.Duration, error) {
-	url := fmt.Sprintf("%s/%d/stop", c.timesURL(), id)
-	var reply struct {
-		Duration  int `json:"duration"`
-		ProjectID int `json:"project_id"`
+	if opts.Description != "" {
+		data["description"] = opts.Description
 	}
-	if err := c.call(http.MethodPatch, url, nil, &reply); err != nil {
-		return -1, 0, err
+
+	if opts.TaskID != 0 {
+		data["task_id"] = opts.TaskID
 	}
 
-	dur := time.Duration(time.Duration(reply.Duration) * time.Second)
-	return reply.ProjectID, dur, nil
-}
+	if len(opts.Tags) > 0 {
+		data["tags"] = opts.Tags
+	}
 
-type Report struct {
-	Project  string
-	Duration time.Duration
-}
+	if opts.Billable != nil {
+		data["billable"] = *opts.Billable
+	}
 
-func (c *Client) Report(since string) ([]Report, error) {
-	u, err := url.Parse("https://api.track.toggl.com/reports/api/v2/summary")
+	body, err := jsonBody(data)
 	if err != nil {
 		return nil, err
 	}
 
-	q := u.Query()
-	q.Set("since", since)
-	q.Set("workspace_id", fmt.Sprintf("%d", c.cfg.WorkspaceID))
-	q.Set("user_agent", "toggl")
-	u.RawQuery = q.Encode()
-
-	var reply struct {
-		Data []struct {
-			Title struct {
-				Project string `json:"project"`
-			} `json:"title"`
-			Time int `json:"time"`
-		} `json:"data"`
-	}
-
-	if err := c.call(http.MethodGet, u.String(), nil, &reply); err != nil {
+	var e TimeEntry
+	if err := c.call(ctx, http.MethodPost, c.timesURL(), body, &e); err != nil {
 		return nil, err
 	}
 
-	var reports []Report
-	for _, project := range reply.Data {
-		d := time.Millisecond * time.Duration(project.Time)
-		reports = append(reports, Report{project.Title.Project, d})
+	return &e, nil
+}
+
+func (c *Client) Stop(ctx context.Context, id int) (int, time.Duration, error) {
+	url := fmt.Sprintf("%s/%d/stop", c.timesURL(), id)
+	var reply struct {
+		Duration  int `json:"duration"`
+		ProjectID int `json:"project_id"`
+	}
+	if err := c.call(ctx, http.MethodPatch, url, nil, &reply); err != nil {
+		return -1, 0, err
 	}
 
-	return reports, nil
+	dur := time.Duration(time.Duration(reply.Duration) * time.Second)
+	return reply.ProjectID, dur, nil
 }
@@ -1,6 +1,9 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -20,7 +23,13 @@ type mockTripper struct {
 // filename should be just the base filename (e.g., "projects.json")
 func loadTestData(t *testing.T, filename string) []byte {
 	t.Helper()
-	path := filepath.Join("testdata", "v8", filename)
+	return loadVersionData(t, "v8", filename)
+}
+
+// loadVersionData loads a JSON test file of an API version from disk
+func loadVersionData(t *testing.T, version, filename string) []byte {
+	t.Helper()
+	path := filepath.Join("testdata", version, filename)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		t.Fatalf("failed to load test data %s: %v", path, err)
@@ -89,6 +98,16 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "bad base URL",
+			config: Config{
+				APIToken:    "token",
+				WorkspaceID: 123,
+				Timeout:     time.Second * 30,
+				BaseURL:     "localhost:8080",
+			},
+			expectError: true,
+		},
 		{
 			name: "invalid timeout",
 			config: Config{
@@ -140,24 +159,75 @@ func TestProjects(t *testing.T) {
 	// First, we need to mock the Projects endpoint
 	c.c.Transport = &mockTripper{data: loadTestData(t, "projects.json")}
 
-	prjs, err := c.Projects()
+	prjs, err := c.Projects(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
 	expected := []Project{
-		{"A", 1, 0, ""},
-		{"B", 2, 0, ""},
+		{Name: "A", ID: 1, WorkspaceID: 100, Active: true, Color: "#990099", ActualHours: 68},
+		{Name: "B", ID: 2, WorkspaceID: 100, Active: true, Color: "#465bb3", ActualHours: 186},
 	}
 	if !slices.Equal(prjs, expected) {
 		t.Errorf("expected %v, got %v", expected, prjs)
 	}
 }
 
+func TestProjectsArchived(t *testing.T) {
+	c := routeServer(t, map[string]route{
+		"GET /me/projects": {data: []byte(`[{"id": 1, "name": "A"}, {"id": 2, "name": "B", "active": false}]`), query: "include_archived=true"},
+		"GET /me/clients":  {data: []byte(`[]`)},
+	})
+
+	prjs, err := c.Projects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(prjs) != 2 || !prjs[0].Active || prjs[1].Active {
+		t.Errorf("bad projects: %+v", prjs)
+	}
+}
+
+func TestProjectJSON(t *testing.T) {
+	data := `{"id": 7, "wid": 1234, "cid": 101, "name": "api", "active": false, "billable": true,
+	  "color": "#06aaf5", "actual_hours": 12, "estimated_hours": 40}`
+
+	var prj Project
+	if err := json.Unmarshal([]byte(data), &prj); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Project{
+		Name:           "api",
+		ID:             7,
+		ClientID:       101,
+		WorkspaceID:    1234,
+		Active:         false,
+		Billable:       true,
+		Color:          "#06aaf5",
+		ActualHours:    12,
+		EstimatedHours: 40,
+	}
+	if prj != expected {
+		t.Errorf("expected %+v, got %+v", expected, prj)
+	}
+
+	// Missing active is active, color index is dropped
+	prj = Project{}
+	if err := json.Unmarshal([]byte(`{"id": 8, "name": "web", "color": "3", "estimated_hours": null}`), &prj); err != nil {
+		t.Fatal(err)
+	}
+
+	if !prj.Active || prj.Color != "" || prj.EstimatedHours != 0 {
+		t.Errorf("bad project: %+v", prj)
+	}
+}
+
 func TestClients(t *testing.T) {
 	c := newClient(t)
 	c.c.Transport = &mockTripper{data: loadTestData(t, "clients.json")}
 
-	clients, err := c.Clients()
+	clients, err := c.Clients(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -216,7 +286,7 @@ func TestTimer(t *testing.T) {
 		c := newClient(t)
 		c.c.Transport = &mockTripper{data: loadTestData(t, "timer.json")}
 
-		timer, err := c.Timer()
+		timer, err := c.Timer(context.Background())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -245,7 +315,7 @@ func TestTimer(t *testing.T) {
 		c := newClient(t)
 		c.c.Transport = &mockTripper{data: loadTestData(t, "timer_empty.json")}
 
-		timer, err := c.Timer()
+		timer, err := c.Timer(context.Background())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -263,10 +333,51 @@ func TestStart(t *testing.T) {
 	projectID := 123
 	startTime := time.Now()
 
-	err := c.Start(projectID, startTime)
+	e, err := c.Start(context.Background(), projectID, startTime, StartOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
+
+	if e.ID != 789 {
+		t.Errorf("expected entry ID 789, got %d", e.ID)
+	}
+}
+
+func TestStartOptions(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "start_timer.json"))
+
+	billable := false
+	opts := StartOptions{
+		Description: "fix bug",
+		Tags:        []string{"dev", "bug"},
+		TaskID:      301,
+		Billable:    &billable,
+	}
+	start := time.Date(2023, 1, 2, 9, 0, 0, 0, time.FixedZone("IST", 2*60*60))
+	if _, err := c.Start(context.Background(), 123, start, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Body["description"] != "fix bug" {
+		t.Errorf("bad description: %v", req.Body["description"])
+	}
+
+	if req.Body["task_id"] != 301.0 {
+		t.Errorf("bad task: %v", req.Body["task_id"])
+	}
+
+	if req.Body["billable"] != false {
+		t.Errorf("bad billable: %v", req.Body["billable"])
+	}
+
+	tags, _ := req.Body["tags"].([]any)
+	if len(tags) != 2 || tags[0] != "dev" || tags[1] != "bug" {
+		t.Errorf("bad tags: %v", req.Body["tags"])
+	}
+
+	if req.Body["start"] != "2023-01-02T07:00:00Z" {
+		t.Errorf("bad start: %v", req.Body["start"])
+	}
 }
 
 func TestStop(t *testing.T) {
@@ -274,7 +385,7 @@ func TestStop(t *testing.T) {
 	c.c.Transport = &mockTripper{data: loadTestData(t, "stop_timer.json")}
 
 	timerID := 456
-	projectID, duration, err := c.Stop(timerID)
+	projectID, duration, err := c.Stop(context.Background(), timerID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -291,21 +402,26 @@ func TestStop(t *testing.T) {
 }
 
 func TestReport(t *testing.T) {
-	c := newClient(t)
-	c.c.Transport = &mockTripper{data: loadTestData(t, "report.json")}
+	c := routeServer(t, map[string]route{
+		"POST /reports/workspace/1234/summary/time_entries": {data: loadVersionData(t, "v3", "summary.json")},
+		"GET /me/projects": {data: loadVersionData(t, "v3", "projects.json")},
+		"GET /me/clients":  {data: loadTestData(t, "clients.json")},
+	})
 
-	reports, err := c.Report("2023-01-01")
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	reports, err := c.Report(context.Background(), since, since.AddDate(0, 0, 6))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if len(reports) != 2 {
-		t.Fatalf("expected 2 reports, got %d", len(reports))
+	if len(reports) != 3 {
+		t.Fatalf("expected 3 reports, got %d", len(reports))
 	}
 
 	expected := []Report{
-		{Project: "Project A", Duration: time.Hour},
+		{Project: "Project A", Client: "Client A", Duration: time.Hour},
 		{Project: "Project B", Duration: 2 * time.Hour},
+		{Project: "", Duration: 10 * time.Minute},
 	}
 
 	for i, report := range reports {
@@ -313,6 +429,10 @@ func TestReport(t *testing.T) {
 			t.Errorf("expected project %q, got %q", expected[i].Project, report.Project)
 		}
 
+		if report.Client != expected[i].Client {
+			t.Errorf("expected client %q, got %q", expected[i].Client, report.Client)
+		}
+
 		if report.Duration != expected[i].Duration {
 			t.Errorf("expected duration %v, got %v", expected[i].Duration, report.Duration)
 		}
@@ -324,12 +444,84 @@ func Test_callHTTPError(t *testing.T) {
 	mt := mockTripper{status: http.StatusBadRequest}
 	c.c.Transport = &mt
 
-	err := c.call("GET", "https://go.dev", nil, nil)
+	err := c.call(context.Background(), "GET", "https://go.dev", nil, nil)
 	if err == nil {
 		t.Fatal("expected error but got nil")
 	}
 }
 
+func TestBaseURL(t *testing.T) {
+	data := loadTestData(t, "timer.json")
+	var path string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(data); err != nil {
+			t.Error(err)
+		}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	defer srv.Close()
+
+	cfg := Config{
+		APIToken:    "api-key",
+		WorkspaceID: 1234,
+		Timeout:     time.Second,
+		BaseURL:     srv.URL + "/api/v9/",
+		HTTPClient:  srv.Client(),
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timer, err := c.Timer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if timer == nil || timer.ID != 456 {
+		t.Fatalf("bad timer: %+v", timer)
+	}
+
+	expected := "/api/v9/me/time_entries/current"
+	if path != expected {
+		t.Errorf("expected path %q, got %q", expected, path)
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	done := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	defer srv.Close()
+	defer close(done)
+
+	cfg := Config{
+		APIToken:    "api-key",
+		WorkspaceID: 1234,
+		Timeout:     time.Minute,
+		BaseURL:     srv.URL,
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = c.Timer(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
 func Test_timesURL(t *testing.T) {
 	c := newClient(t)
 	url := c.timesURL()
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Customer is a toggl client (Client is the API client)
+type Customer struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	WorkspaceID int    `json:"wid"`
+}
+
+func (c *Client) clientsURL() string {
+	return fmt.Sprintf("%s/workspaces/%d/clients", c.baseURL, c.cfg.WorkspaceID)
+}
+
+// Customers returns the workspace clients
+func (c *Client) Customers(ctx context.Context) ([]Customer, error) {
+	var cs []Customer
+	if err := c.call(ctx, http.MethodGet, c.clientsURL(), nil, &cs); err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// CreateClient creates a new client
+func (c *Client) CreateClient(ctx context.Context, name string) (*Customer, error) {
+	if name == "" {
+		return nil, fmt.Errorf("create client: empty name")
+	}
+
+	body, err := jsonBody(map[string]any{"name": name, "wid": c.cfg.WorkspaceID})
+	if err != nil {
+		return nil, err
+	}
+
+	var cust Customer
+	if err := c.call(ctx, http.MethodPost, c.clientsURL(), body, &cust); err != nil {
+		return nil, err
+	}
+
+	return &cust, nil
+}
+
+// RenameClient renames client id to name
+func (c *Client) RenameClient(ctx context.Context, id int, name string) (*Customer, error) {
+	if name == "" {
+		return nil, fmt.Errorf("rename client: empty name")
+	}
+
+	body, err := jsonBody(map[string]any{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/%d", c.clientsURL(), id)
+	var cust Customer
+	if err := c.call(ctx, http.MethodPut, url, body, &cust); err != nil {
+		return nil, err
+	}
+
+	return &cust, nil
+}
+
+// DeleteClient deletes client id
+func (c *Client) DeleteClient(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/%d", c.clientsURL(), id)
+	return c.call(ctx, http.MethodDelete, url, nil, nil)
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCustomers(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "clients.json"))
+
+	cs, err := c.Customers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "GET" || req.Path != "/workspaces/1234/clients" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	if len(cs) != 2 || cs[0] != (Customer{ID: 101, Name: "Client A"}) {
+		t.Errorf("bad clients: %+v", cs)
+	}
+}
+
+func TestCreateClient(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "client.json"))
+
+	cust, err := c.CreateClient(context.Background(), "Client C")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "POST" || req.Path != "/workspaces/1234/clients" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	if req.Body["name"] != "Client C" || req.Body["wid"] != 1234.0 {
+		t.Errorf("bad body: %v", req.Body)
+	}
+
+	expected := Customer{ID: 103, Name: "Client C", WorkspaceID: 1234}
+	if *cust != expected {
+		t.Errorf("expected %+v, got %+v", expected, *cust)
+	}
+}
+
+func TestRenameClient(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "client.json"))
+
+	if _, err := c.RenameClient(context.Background(), 103, "Client C"); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "PUT" || req.Path != "/workspaces/1234/clients/103" || req.Body["name"] != "Client C" {
+		t.Errorf("bad request: %+v", req)
+	}
+
+	if _, err := c.RenameClient(context.Background(), 103, ""); err == nil {
+		t.Error("expected error on empty name, got nil")
+	}
+}
+
+func TestDeleteClient(t *testing.T) {
+	c, req := recordServer(t, nil)
+
+	if err := c.DeleteClient(context.Background(), 103); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "DELETE" || req.Path != "/workspaces/1234/clients/103" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TimeEntry is a toggl time entry
+type TimeEntry struct {
+	ID          int        `json:"id"`
+	WorkspaceID int        `json:"workspace_id"`
+	ProjectID   int        `json:"project_id"`
+	TaskID      int        `json:"task_id"`
+	Description string     `json:"description"`
+	Tags        []string   `json:"tags"`
+	Billable    bool       `json:"billable"`
+	Start       time.Time  `json:"start"`
+	Stop        *time.Time `json:"stop"`
+	// Duration is in seconds, negative if the entry is running
+	Duration int64 `json:"duration"`
+}
+
+// Running returns true if the time entry is still running
+func (e TimeEntry) Running() bool {
+	return e.Stop == nil && e.Duration < 0
+}
+
+// Elapsed returns the entry duration, for running entries it's the time since start
+func (e TimeEntry) Elapsed() time.Duration {
+	if e.Running() {
+		return time.Since(e.Start)
+	}
+
+	if e.Stop != nil {
+		return e.Stop.Sub(e.Start)
+	}
+
+	return time.Duration(e.Duration) * time.Second
+}
+
+// payload returns the API representation of e for create & update
+func (e TimeEntry) payload(wid int) map[string]any {
+	if e.WorkspaceID != 0 {
+		wid = e.WorkspaceID
+	}
+
+	tags := e.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	data := map[string]any{
+		"created_with": createdWith,
+		"workspace_id": wid,
+		"project_id":   nil,
+		"task_id":      nil,
+		"description":  e.Description,
+		"tags":         tags,
+		"billable":     e.Billable,
+		"start":        e.Start.UTC().Format(time.RFC3339),
+	}
+
+	if e.ProjectID != 0 {
+		data["project_id"] = e.ProjectID
+	}
+
+	if e.TaskID != 0 {
+		data["task_id"] = e.TaskID
+	}
+
+	switch {
+	case e.Stop != nil:
+		data["stop"] = e.Stop.UTC().Format(time.RFC3339)
+		data["duration"] = int64(e.Stop.Sub(e.Start).Seconds())
+	case e.Duration > 0:
+		data["duration"] = e.Duration
+	default:
+		data["duration"] = -1
+	}
+
+	return data
+}
+
+// TimeEntries returns the current user time entries between since and until.
+// If both are zero, toggl returns the recent time entries.
+func (c *Client) TimeEntries(ctx context.Context, since, until time.Time) ([]TimeEntry, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/me/time_entries", c.baseURL))
+	if err != nil {
+		return nil, err
+	}
+
+	if !since.IsZero() || !until.IsZero() {
+		if until.IsZero() {
+			until = time.Now()
+		}
+
+		q := u.Query()
+		q.Set("start_date", since.UTC().Format(time.RFC3339))
+		q.Set("end_date", until.UTC().Format(time.RFC3339))
+		u.RawQuery = q.Encode()
+	}
+
+	var entries []TimeEntry
+	if err := c.call(ctx, http.MethodGet, u.String(), nil, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// TimeEntry returns the time entry with id
+func (c *Client) TimeEntry(ctx context.Context, id int) (*TimeEntry, error) {
+	url := fmt.Sprintf("%s/me/time_entries/%d", c.baseURL, id)
+	var e TimeEntry
+	if err := c.call(ctx, http.MethodGet, url, nil, &e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// CreateTimeEntry creates a new time entry, a zero e.Stop and e.Duration create a running entry
+func (c *Client) CreateTimeEntry(ctx context.Context, e TimeEntry) (*TimeEntry, error) {
+	body, err := jsonBody(e.payload(c.cfg.WorkspaceID))
+	if err != nil {
+		return nil, err
+	}
+
+	var out TimeEntry
+	if err := c.call(ctx, http.MethodPost, c.timesURL(), body, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// UpdateTimeEntry replaces the time entry e.ID with e
+func (c *Client) UpdateTimeEntry(ctx context.Context, e TimeEntry) (*TimeEntry, error) {
+	if e.ID == 0 {
+		return nil, fmt.Errorf("update: missing time entry ID")
+	}
+
+	body, err := jsonBody(e.payload(c.cfg.WorkspaceID))
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/%d", c.timesURL(), e.ID)
+	var out TimeEntry
+	if err := c.call(ctx, http.MethodPut, url, body, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// DeleteTimeEntry deletes the time entry with id
+func (c *Client) DeleteTimeEntry(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/%d", c.timesURL(), id)
+	return c.call(ctx, http.MethodDelete, url, nil, nil)
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// request is an HTTP request recorded by recordServer
+type request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   map[string]any
+}
+
+// recordServer returns a client talking to a server that replies with data
+// and records the last request
+func recordServer(t *testing.T, data []byte) (*Client, *request) {
+	t.Helper()
+
+	var last request
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		last = request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(body) > 0 {
+			if err := json.Unmarshal(body, &last.Body); err != nil {
+				t.Errorf("bad request body: %s", err)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(data); err != nil {
+			t.Error(err)
+		}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+
+	cfg := Config{
+		APIToken:    "api-key",
+		WorkspaceID: 1234,
+		Timeout:     time.Second,
+		BaseURL:     srv.URL,
+		ReportsURL:  srv.URL + "/reports",
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, &last
+}
+
+func TestTimeEntries(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "time_entries.json"))
+
+	since := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 0, 1)
+	entries, err := c.TimeEntries(context.Background(), since, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Path != "/me/time_entries" {
+		t.Errorf("bad path: %q", req.Path)
+	}
+
+	expectedQuery := "end_date=2023-01-03T00%3A00%3A00Z&start_date=2023-01-02T00%3A00%3A00Z"
+	if req.Query != expectedQuery {
+		t.Errorf("expected query %q, got %q", expectedQuery, req.Query)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	running, done := entries[0], entries[1]
+	if !running.Running() {
+		t.Errorf("expected %+v to be running", running)
+	}
+
+	if done.Running() {
+		t.Errorf("expected %+v to be stopped", done)
+	}
+
+	if d := done.Elapsed(); d != 90*time.Minute {
+		t.Errorf("expected 1h30m, got %v", d)
+	}
+
+	if done.TaskID != 11 || !done.Billable || !slices.Equal(done.Tags, []string{"dev", "backend"}) {
+		t.Errorf("bad entry: %+v", done)
+	}
+}
+
+func TestTimeEntry(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "time_entry.json"))
+
+	e, err := c.TimeEntry(context.Background(), 1001)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != http.MethodGet || req.Path != "/me/time_entries/1001" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	if e.ID != 1001 || e.Description != "api" {
+		t.Errorf("bad entry: %+v", e)
+	}
+}
+
+func TestCreateTimeEntry(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "time_entry.json"))
+
+	start := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+	stop := start.Add(90 * time.Minute)
+	e := TimeEntry{
+		ProjectID:   1,
+		Description: "api",
+		Tags:        []string{"dev"},
+		Start:       start,
+		Stop:        &stop,
+	}
+
+	out, err := c.CreateTimeEntry(context.Background(), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.ID != 1001 {
+		t.Errorf("bad reply: %+v", out)
+	}
+
+	if req.Method != http.MethodPost || req.Path != "/workspaces/1234/time_entries" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	expected := map[string]any{
+		"workspace_id": 1234.0,
+		"project_id":   1.0,
+		"duration":     5400.0,
+		"start":        "2023-01-02T09:00:00Z",
+		"stop":         "2023-01-02T10:30:00Z",
+		"description":  "api",
+		"task_id":      nil,
+	}
+	for k, v := range expected {
+		if req.Body[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, req.Body[k])
+		}
+	}
+}
+
+func TestUpdateTimeEntry(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "time_entry.json"))
+
+	e := TimeEntry{
+		ID:       1001,
+		Start:    time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC),
+		Duration: -1,
+	}
+
+	if _, err := c.UpdateTimeEntry(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != http.MethodPut || req.Path != "/workspaces/1234/time_entries/1001" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	if req.Body["duration"] != -1.0 {
+		t.Errorf("expected running duration, got %v", req.Body["duration"])
+	}
+
+	if _, err := c.UpdateTimeEntry(context.Background(), TimeEntry{}); err == nil {
+		t.Error("expected error on missing ID")
+	}
+}
+
+func TestDeleteTimeEntry(t *testing.T) {
+	c, req := recordServer(t, nil)
+
+	if err := c.DeleteTimeEntry(context.Background(), 1001); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != http.MethodDelete || req.Path != "/workspaces/1234/time_entries/1001" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+}
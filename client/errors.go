@@ -0,0 +1,120 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnauthorized is returned on bad API token or missing permissions
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound is returned when requested resource does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is returned when toggl throttles requests
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// maxErrorBody is the maximal number of error body bytes we read
+const maxErrorBody = 64 << 10
+
+// APIError is an HTTP error returned from the toggl API.
+// Use errors.Is with ErrUnauthorized, ErrNotFound and ErrRateLimited to check
+// for common cases.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	// Message is the error message toggl sent in the response body
+	Message string
+	// RetryAfter is the value of the Retry-After header (0 if missing)
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %q: %s", e.Method, e.URL, e.Status)
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s - %s", msg, e.Message)
+	}
+	return msg
+}
+
+// Is implements errors.Is matching with the package sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+	e := APIError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err == nil {
+		e.Message = errorMessage(data)
+	}
+
+	return &e
+}
+
+// errorMessage extracts error message from response body.
+// toggl returns either a JSON string, a JSON object or plain text.
+func errorMessage(data []byte) string {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+
+	var obj struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &obj); err == nil {
+		if obj.Message != "" {
+			return obj.Message
+		}
+		return obj.Error
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// parseRetryAfter parses Retry-After header, which is either seconds or HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	t, err := http.ParseTime(value)
+	if err != nil || t.Before(now) {
+		return 0
+	}
+
+	return t.Sub(now)
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAPIError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		sentinel error
+		message  string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `"Incorrect username and/or password"`, ErrUnauthorized, "Incorrect username and/or password"},
+		{"forbidden", http.StatusForbidden, `{"message": "no access"}`, ErrUnauthorized, "no access"},
+		{"not found", http.StatusNotFound, "Not Found\n", ErrNotFound, "Not Found"},
+		{"rate limited", http.StatusTooManyRequests, `{"error": "slow down"}`, ErrRateLimited, "slow down"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newClient(t)
+			c.c.Transport = &mockTripper{data: []byte(tc.body), status: tc.status}
+
+			_, err := c.Timer(context.Background())
+			if !errors.Is(err, tc.sentinel) {
+				t.Fatalf("expected %v, got %v", tc.sentinel, err)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected APIError, got %T", err)
+			}
+
+			if apiErr.StatusCode != tc.status {
+				t.Errorf("expected status %d, got %d", tc.status, apiErr.StatusCode)
+			}
+
+			if apiErr.Message != tc.message {
+				t.Errorf("expected message %q, got %q", tc.message, apiErr.Message)
+			}
+
+			if apiErr.Method != http.MethodGet {
+				t.Errorf("expected method GET, got %q", apiErr.Method)
+			}
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"Sun, 01 Jan 2023 12:00:10 GMT", 10 * time.Second},
+		{"Sun, 01 Jan 2023 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			d := parseRetryAfter(tc.value, now)
+			if d != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, d)
+			}
+		})
+	}
+}
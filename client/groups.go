@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GroupBy is a grouped report key
+type GroupBy string
+
+const (
+	GroupByProject     GroupBy = "project"
+	GroupByClient      GroupBy = "client"
+	GroupByTask        GroupBy = "task"
+	GroupByTag         GroupBy = "tag"
+	GroupByDescription GroupBy = "description"
+	GroupByUser        GroupBy = "user"
+	GroupByDay         GroupBy = "day"
+)
+
+// GroupByKeys are the supported grouped report keys
+var GroupByKeys = []GroupBy{
+	GroupByProject,
+	GroupByClient,
+	GroupByTask,
+	GroupByTag,
+	GroupByDescription,
+	GroupByUser,
+	GroupByDay,
+}
+
+// ParseGroupBy parses a grouped report key
+func ParseGroupBy(s string) (GroupBy, error) {
+	for _, g := range GroupByKeys {
+		if strings.EqualFold(s, string(g)) {
+			return g, nil
+		}
+	}
+
+	names := make([]string, len(GroupByKeys))
+	for i, g := range GroupByKeys {
+		names[i] = string(g)
+	}
+	return "", fmt.Errorf("unknown group %q (should be one of %s)", s, strings.Join(names, ", "))
+}
+
+// ReportGroup is total time of a group in a grouped report
+type ReportGroup struct {
+	Title     string
+	Duration  time.Duration
+	SubGroups []ReportGroup
+}
+
+// groupNames are used to resolve IDs in detailed entries to titles
+type groupNames struct {
+	projects map[int]Project
+	tags     map[int]string
+	tasks    map[int]string
+	loc      *time.Location
+}
+
+// keys returns the group titles of e, an entry with several tags is in several groups
+func (n groupNames) keys(e DetailedEntry, by GroupBy) []string {
+	switch by {
+	case GroupByProject:
+		if prj, ok := n.projects[e.ProjectID]; ok {
+			return []string{prj.FullName()}
+		}
+		return []string{"(no project)"}
+	case GroupByClient:
+		if prj, ok := n.projects[e.ProjectID]; ok && prj.ClientName != "" {
+			return []string{prj.ClientName}
+		}
+		return []string{"(no client)"}
+	case GroupByTask:
+		if e.TaskID == 0 {
+			return []string{"(no task)"}
+		}
+		if name := n.tasks[e.TaskID]; name != "" {
+			return []string{name}
+		}
+		return []string{fmt.Sprintf("(task %d)", e.TaskID)}
+	case GroupByTag:
+		if len(e.TagIDs) == 0 {
+			return []string{"(no tag)"}
+		}
+		keys := make([]string, len(e.TagIDs))
+		for i, id := range e.TagIDs {
+			keys[i] = n.tags[id]
+			if keys[i] == "" {
+				keys[i] = fmt.Sprintf("(tag %d)", id)
+			}
+		}
+		return keys
+	case GroupByDescription:
+		if e.Description == "" {
+			return []string{"(no description)"}
+		}
+		return []string{e.Description}
+	case GroupByUser:
+		if e.Username != "" {
+			return []string{e.Username}
+		}
+		return []string{fmt.Sprintf("(user %d)", e.UserID)}
+	case GroupByDay:
+		return []string{e.Start.In(n.loc).Format("2006-01-02")}
+	}
+
+	return nil
+}
+
+// groupEntries groups entries by group and then by subGroup (if not empty)
+func groupEntries(entries []DetailedEntry, names groupNames, group, subGroup GroupBy) []ReportGroup {
+	byKey := make(map[string][]DetailedEntry)
+	for _, e := range entries {
+		for _, key := range names.keys(e, group) {
+			byKey[key] = append(byKey[key], e)
+		}
+	}
+
+	groups := make([]ReportGroup, 0, len(byKey))
+	for key, es := range byKey {
+		g := ReportGroup{Title: key}
+		for _, e := range es {
+			g.Duration += e.Duration
+		}
+
+		if subGroup != "" {
+			g.SubGroups = groupEntries(es, names, subGroup, "")
+		}
+		groups = append(groups, g)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if group == GroupByDay || groups[i].Duration == groups[j].Duration {
+			return groups[i].Title < groups[j].Title
+		}
+		return groups[i].Duration > groups[j].Duration
+	})
+
+	return groups
+}
+
+// GroupedReport returns time between since and until (inclusive dates) grouped by group
+// and then by subGroup (if not empty). Days are in since's location.
+func (c *Client) GroupedReport(ctx context.Context, since, until time.Time, group, subGroup GroupBy) ([]ReportGroup, error) {
+	if group == "" {
+		return nil, fmt.Errorf("missing group")
+	}
+
+	entries, err := c.Detailed(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	names := groupNames{loc: since.Location()}
+	if group == GroupByProject || group == GroupByClient || subGroup == GroupByProject || subGroup == GroupByClient {
+		prjs, err := c.Projects(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		names.projects = make(map[int]Project, len(prjs))
+		for _, prj := range prjs {
+			names.projects[prj.ID] = prj
+		}
+	}
+
+	if group == GroupByTag || subGroup == GroupByTag {
+		if names.tags, err = c.tagNames(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if group == GroupByTask || subGroup == GroupByTask {
+		var pids []int
+		for _, e := range entries {
+			if e.TaskID != 0 {
+				pids = append(pids, e.ProjectID)
+			}
+		}
+
+		if names.tasks, err = c.TaskNames(ctx, pids); err != nil {
+			return nil, err
+		}
+	}
+
+	return groupEntries(entries, names, group, subGroup), nil
+}
+
+// tagNames returns map of tag ID -> name
+func (c *Client) tagNames(ctx context.Context) (map[int]string, error) {
+	tags, err := c.Tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(tags))
+	for _, t := range tags {
+		names[t.ID] = t.Name
+	}
+	return names, nil
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseGroupBy(t *testing.T) {
+	g, err := ParseGroupBy("Client")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g != GroupByClient {
+		t.Errorf("expected %q, got %q", GroupByClient, g)
+	}
+
+	if _, err := ParseGroupBy("weather"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestGroupedReport(t *testing.T) {
+	detailed := loadVersionData(t, "v3", "detailed_1.json")
+	routes := map[string]route{
+		"POST /reports/workspace/1234/search/time_entries#1": {data: detailed},
+		"POST /reports/workspace/1234/search/time_entries#2": {data: detailed},
+		"POST /reports/workspace/1234/search/time_entries#3": {data: detailed},
+		"GET /me/projects":                      {data: loadVersionData(t, "v3", "projects.json")},
+		"GET /me/clients":                       {data: loadTestData(t, "clients.json")},
+		"GET /workspaces/1234/tags":             {data: loadTestData(t, "tags.json")},
+		"GET /workspaces/1234/projects/1/tasks": {data: loadTestData(t, "tasks.json")},
+	}
+	c := routeServer(t, routes)
+
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	groups, err := c.GroupedReport(context.Background(), since, since.AddDate(0, 0, 6), GroupByClient, GroupByDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 group, got %+v", groups)
+	}
+
+	g := groups[0]
+	if g.Title != "Client A" || g.Duration != 30*time.Minute || len(g.SubGroups) != 2 {
+		t.Fatalf("bad group: %+v", g)
+	}
+
+	if sg := g.SubGroups[0]; sg.Title != "2023-01-02" || sg.Duration != 15*time.Minute {
+		t.Errorf("bad sub group: %+v", sg)
+	}
+
+	groups, err = c.GroupedReport(context.Background(), since, since.AddDate(0, 0, 6), GroupByTag, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 1 || groups[0].Title != "dev" || groups[0].SubGroups != nil {
+		t.Errorf("bad tag groups: %+v", groups)
+	}
+
+	groups, err = c.GroupedReport(context.Background(), since, since.AddDate(0, 0, 6), GroupByTask, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 1 || groups[0].Title != "backend" || groups[0].Duration != 30*time.Minute {
+		t.Errorf("bad task groups: %+v", groups)
+	}
+}
+
+func Test_groupEntries(t *testing.T) {
+	loc := time.FixedZone("IST", 2*60*60)
+	entry := func(desc string, tags []int, day, hour int, dur time.Duration) DetailedEntry {
+		return DetailedEntry{
+			Description: desc,
+			TagIDs:      tags,
+			Start:       time.Date(2023, 1, day, hour, 0, 0, 0, time.UTC),
+			Duration:    dur,
+		}
+	}
+
+	entries := []DetailedEntry{
+		entry("a", []int{1, 2}, 2, 9, time.Hour),
+		entry("b", []int{1}, 2, 23, 2*time.Hour), // 01:00 on the 3rd in IST
+		entry("", nil, 3, 9, 30*time.Minute),
+	}
+	names := groupNames{
+		tags: map[int]string{1: "dev", 2: "ops"},
+		loc:  loc,
+	}
+
+	groups := groupEntries(entries, names, GroupByTag, GroupByDescription)
+	titles := func(gs []ReportGroup) []string {
+		var out []string
+		for _, g := range gs {
+			out = append(out, g.Title)
+		}
+		return out
+	}
+
+	expected := []string{"dev", "ops", "(no tag)"}
+	if got := titles(groups); len(got) != 3 || got[0] != expected[0] || got[1] != expected[1] || got[2] != expected[2] {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	dev := groups[0]
+	if dev.Duration != 3*time.Hour || len(dev.SubGroups) != 2 || dev.SubGroups[0].Title != "b" {
+		t.Errorf("bad dev group: %+v", dev)
+	}
+
+	days := groupEntries(entries, names, GroupByDay, "")
+	if got := titles(days); len(got) != 2 || got[0] != "2023-01-02" || got[1] != "2023-01-03" {
+		t.Fatalf("bad days: %v", got)
+	}
+
+	if days[1].Duration != 150*time.Minute {
+		t.Errorf("expected 2h30m on the 3rd, got %v", days[1].Duration)
+	}
+
+	// Projects with the same name under different clients are different groups
+	names.projects = map[int]Project{
+		1: {ID: 1, Name: "site", ClientName: "Acme"},
+		2: {ID: 2, Name: "site", ClientName: "Globex"},
+	}
+	entries = []DetailedEntry{
+		{ProjectID: 1, Duration: time.Hour},
+		{ProjectID: 2, Duration: 2 * time.Hour},
+	}
+
+	prjs := groupEntries(entries, names, GroupByProject, "")
+	if got := titles(prjs); len(got) != 2 || got[0] != "Globex/site" || got[1] != "Acme/site" {
+		t.Errorf("bad projects: %v", got)
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ProjectOptions are optional fields of a new project
+type ProjectOptions struct {
+	// ClientID is 0 for a project without a client
+	ClientID int
+	// Color is a hex color (e.g. "#06aaf5"), empty for toggl's default
+	Color string
+}
+
+// ProjectUpdate is a project change, nil fields are not changed
+type ProjectUpdate struct {
+	Name     *string
+	ClientID *int
+	Active   *bool
+	Color    *string
+}
+
+// payload returns the API representation of u
+func (u ProjectUpdate) payload() map[string]any {
+	data := make(map[string]any)
+	if u.Name != nil {
+		data["name"] = *u.Name
+	}
+
+	if u.ClientID != nil {
+		data["client_id"] = nil
+		if *u.ClientID != 0 {
+			data["client_id"] = *u.ClientID
+		}
+	}
+
+	if u.Active != nil {
+		data["active"] = *u.Active
+	}
+
+	if u.Color != nil {
+		data["color"] = *u.Color
+	}
+
+	return data
+}
+
+func (c *Client) projectsURL() string {
+	return fmt.Sprintf("%s/workspaces/%d/projects", c.baseURL, c.cfg.WorkspaceID)
+}
+
+// CreateProject creates a new active project
+func (c *Client) CreateProject(ctx context.Context, name string, opts ProjectOptions) (*Project, error) {
+	if name == "" {
+		return nil, fmt.Errorf("create project: empty name")
+	}
+
+	data := map[string]any{
+		"name":   name,
+		"active": true,
+	}
+
+	if opts.ClientID != 0 {
+		data["client_id"] = opts.ClientID
+	}
+
+	if opts.Color != "" {
+		data["color"] = opts.Color
+	}
+
+	body, err := jsonBody(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var prj Project
+	if err := c.call(ctx, http.MethodPost, c.projectsURL(), body, &prj); err != nil {
+		return nil, err
+	}
+
+	return &prj, nil
+}
+
+// UpdateProject changes project id with the non nil fields of u
+func (c *Client) UpdateProject(ctx context.Context, id int, u ProjectUpdate) (*Project, error) {
+	data := u.payload()
+	if len(data) == 0 {
+		return nil, fmt.Errorf("update project: nothing to update")
+	}
+
+	body, err := jsonBody(data)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/%d", c.projectsURL(), id)
+	var prj Project
+	if err := c.call(ctx, http.MethodPut, url, body, &prj); err != nil {
+		return nil, err
+	}
+
+	return &prj, nil
+}
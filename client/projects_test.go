@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCreateProject(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "project.json"))
+
+	prj, err := c.CreateProject(context.Background(), "New Project", ProjectOptions{ClientID: 101, Color: "#06aaf5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "POST" || req.Path != "/workspaces/1234/projects" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	expected := map[string]any{
+		"name":      "New Project",
+		"active":    true,
+		"client_id": 101.0,
+		"color":     "#06aaf5",
+	}
+	for k, v := range expected {
+		if req.Body[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, req.Body[k])
+		}
+	}
+
+	if prj.ID != 7 || prj.Name != "New Project" || prj.ClientID != 101 {
+		t.Errorf("bad project: %+v", prj)
+	}
+
+	if _, err := c.CreateProject(context.Background(), "", ProjectOptions{}); err == nil {
+		t.Error("expected error on empty name, got nil")
+	}
+}
+
+func TestUpdateProject(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "project.json"))
+
+	active, noClient := false, 0
+	u := ProjectUpdate{Active: &active, ClientID: &noClient}
+	if _, err := c.UpdateProject(context.Background(), 7, u); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "PUT" || req.Path != "/workspaces/1234/projects/7" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	if len(req.Body) != 2 || req.Body["active"] != false {
+		t.Errorf("bad body: %v", req.Body)
+	}
+
+	if v, ok := req.Body["client_id"]; !ok || v != nil {
+		t.Errorf("expected null client_id, got %v", req.Body)
+	}
+
+	if _, err := c.UpdateProject(context.Background(), 7, ProjectUpdate{}); err == nil {
+		t.Error("expected error on empty update, got nil")
+	}
+}
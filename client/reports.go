@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// detailedPageSize is the number of rows per detailed report page
+const detailedPageSize = 200
+
+// Report is total time per project
+type Report struct {
+	Project  string
+	Client   string
+	Duration time.Duration
+}
+
+// SummaryGroup is project total time in a summary report, with time per description
+type SummaryGroup struct {
+	// ProjectID is 0 for time without a project
+	ProjectID int
+	Project   string
+	Client    string
+	Duration  time.Duration
+	SubGroups []SummarySubGroup
+}
+
+// SummarySubGroup is total time of a description in a SummaryGroup
+type SummarySubGroup struct {
+	Description string
+	Duration    time.Duration
+}
+
+// DetailedEntry is a time entry in a detailed report
+type DetailedEntry struct {
+	ID          int
+	UserID      int
+	Username    string
+	ProjectID   int
+	TaskID      int
+	Description string
+	TagIDs      []int
+	Billable    bool
+	Start       time.Time
+	Stop        time.Time
+	Duration    time.Duration
+}
+
+// WeeklyRow is a per user and project row in a weekly report
+type WeeklyRow struct {
+	UserID    int
+	ProjectID int
+	// Days is the time per day, starting at the report start
+	Days [7]time.Duration
+}
+
+// Total returns the total time in the row
+func (r WeeklyRow) Total() time.Duration {
+	var total time.Duration
+	for _, d := range r.Days {
+		total += d
+	}
+	return total
+}
+
+func (c *Client) reportURL(kind string) string {
+	return fmt.Sprintf("%s/workspace/%d/%s/time_entries", c.reportsURL, c.cfg.WorkspaceID, kind)
+}
+
+// reportFilter returns the common report request filter between since and until (inclusive dates)
+func reportFilter(since, until time.Time) map[string]any {
+	filter := map[string]any{
+		"start_date": since.Format("2006-01-02"),
+	}
+
+	if !until.IsZero() {
+		filter["end_date"] = until.Format("2006-01-02")
+	}
+
+	return filter
+}
+
+// Report returns per project time between since and until (inclusive dates).
+// A zero until means up to today.
+func (c *Client) Report(ctx context.Context, since, until time.Time) ([]Report, error) {
+	groups, err := c.Summary(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]Report, len(groups))
+	for i, g := range groups {
+		reports[i] = Report{g.Project, g.Client, g.Duration}
+	}
+
+	return reports, nil
+}
+
+// Summary returns time per project and description between since and until (inclusive dates)
+func (c *Client) Summary(ctx context.Context, since, until time.Time) ([]SummaryGroup, error) {
+	filter := reportFilter(since, until)
+	filter["grouping"] = "projects"
+	filter["sub_grouping"] = "time_entries"
+
+	body, err := jsonBody(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply struct {
+		Groups []struct {
+			ID        *int `json:"id"`
+			SubGroups []struct {
+				Title   string `json:"title"`
+				Seconds int64  `json:"seconds"`
+			} `json:"sub_groups"`
+		} `json:"groups"`
+	}
+
+	if _, err := c.report(ctx, c.reportURL("summary"), body, &reply); err != nil {
+		return nil, err
+	}
+
+	// The summary report has only project IDs
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]Project, len(prjs))
+	for _, prj := range prjs {
+		byID[prj.ID] = prj
+	}
+
+	groups := make([]SummaryGroup, len(reply.Groups))
+	for i, rg := range reply.Groups {
+		g := &groups[i]
+		if rg.ID != nil {
+			g.ProjectID = *rg.ID
+			prj := byID[g.ProjectID]
+			g.Project, g.Client = prj.Name, prj.ClientName
+		}
+
+		g.SubGroups = make([]SummarySubGroup, len(rg.SubGroups))
+		for j, sg := range rg.SubGroups {
+			d := time.Duration(sg.Seconds) * time.Second
+			g.SubGroups[j] = SummarySubGroup{sg.Title, d}
+			g.Duration += d
+		}
+	}
+
+	return groups, nil
+}
+
+// Detailed returns all time entries between since and until (inclusive dates)
+func (c *Client) Detailed(ctx context.Context, since, until time.Time) ([]DetailedEntry, error) {
+	var entries []DetailedEntry
+	row := 0
+	for {
+		filter := reportFilter(since, until)
+		filter["page_size"] = detailedPageSize
+		if row > 0 {
+			filter["first_row_number"] = row
+		}
+
+		body, err := jsonBody(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		var reply []struct {
+			UserID      int    `json:"user_id"`
+			Username    string `json:"username"`
+			ProjectID   int    `json:"project_id"`
+			TaskID      int    `json:"task_id"`
+			Description string `json:"description"`
+			TagIDs      []int  `json:"tag_ids"`
+			Billable    bool   `json:"billable"`
+			TimeEntries []struct {
+				ID      int       `json:"id"`
+				Seconds int64     `json:"seconds"`
+				Start   time.Time `json:"start"`
+				Stop    time.Time `json:"stop"`
+			} `json:"time_entries"`
+		}
+
+		hdr, err := c.report(ctx, c.reportURL("search"), body, &reply)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range reply {
+			for _, te := range r.TimeEntries {
+				e := DetailedEntry{
+					ID:          te.ID,
+					UserID:      r.UserID,
+					Username:    r.Username,
+					ProjectID:   r.ProjectID,
+					TaskID:      r.TaskID,
+					Description: r.Description,
+					TagIDs:      r.TagIDs,
+					Billable:    r.Billable,
+					Start:       te.Start,
+					Stop:        te.Stop,
+					Duration:    time.Duration(te.Seconds) * time.Second,
+				}
+				entries = append(entries, e)
+			}
+		}
+
+		next := hdr.Get("X-Next-Row-Number")
+		if next == "" {
+			return entries, nil
+		}
+
+		n, err := strconv.Atoi(next)
+		if err != nil || n <= row {
+			return nil, fmt.Errorf("detailed report: bad next row number %q", next)
+		}
+		row = n
+	}
+}
+
+// Weekly returns the 7 days weekly report starting at since
+func (c *Client) Weekly(ctx context.Context, since time.Time) ([]WeeklyRow, error) {
+	body, err := jsonBody(reportFilter(since, time.Time{}))
+	if err != nil {
+		return nil, err
+	}
+
+	var reply []struct {
+		UserID    int     `json:"user_id"`
+		ProjectID int     `json:"project_id"`
+		Seconds   []int64 `json:"seconds"`
+	}
+
+	if _, err := c.report(ctx, c.reportURL("weekly"), body, &reply); err != nil {
+		return nil, err
+	}
+
+	rows := make([]WeeklyRow, len(reply))
+	for i, r := range reply {
+		rows[i] = WeeklyRow{UserID: r.UserID, ProjectID: r.ProjectID}
+		for day, secs := range r.Seconds {
+			if day >= len(rows[i].Days) {
+				break
+			}
+			rows[i].Days[day] = time.Duration(secs) * time.Second
+		}
+	}
+
+	return rows, nil
+}
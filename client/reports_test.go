@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// route is a canned server reply
+type route struct {
+	data   []byte
+	header map[string]string
+	// status is the reply status code, 0 is OK
+	status int
+	// query is the expected raw query, if not empty
+	query string
+	// check is called with the request body
+	check func(t *testing.T, body map[string]any)
+}
+
+// routeServer returns a client talking to a server replying by "METHOD path".
+// Routes ending with "#" followed by a number are used for the Nth call (e.g. "POST /x#2").
+func routeServer(t *testing.T, routes map[string]route) *Client {
+	t.Helper()
+
+	calls := make(map[string]int)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		calls[key]++
+		rt, ok := routes[key]
+		if !ok {
+			rt, ok = routes[fmt.Sprintf("%s#%d", key, calls[key])]
+		}
+		if !ok {
+			t.Errorf("unexpected call: %s", key)
+			http.NotFound(w, r)
+			return
+		}
+
+		if rt.query != "" && r.URL.RawQuery != rt.query {
+			t.Errorf("%s: expected query %q, got %q", key, rt.query, r.URL.RawQuery)
+		}
+
+		if rt.check != nil {
+			var body map[string]any
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Errorf("%s: bad body - %s", key, err)
+			}
+			rt.check(t, body)
+		}
+
+		for k, v := range rt.header {
+			w.Header().Set(k, v)
+		}
+		if rt.status != 0 {
+			http.Error(w, http.StatusText(rt.status), rt.status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(rt.data); err != nil {
+			t.Error(err)
+		}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+
+	cfg := Config{
+		APIToken:    "api-key",
+		WorkspaceID: 1234,
+		Timeout:     time.Second,
+		BaseURL:     srv.URL,
+		ReportsURL:  srv.URL + "/reports",
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestSummary(t *testing.T) {
+	check := func(t *testing.T, body map[string]any) {
+		expected := map[string]any{
+			"start_date":   "2023-01-01",
+			"end_date":     "2023-01-07",
+			"grouping":     "projects",
+			"sub_grouping": "time_entries",
+		}
+		for k, v := range expected {
+			if body[k] != v {
+				t.Errorf("%s: expected %v, got %v", k, v, body[k])
+			}
+		}
+	}
+
+	c := routeServer(t, map[string]route{
+		"POST /reports/workspace/1234/summary/time_entries": {data: loadVersionData(t, "v3", "summary.json"), check: check},
+		"GET /me/projects": {data: loadVersionData(t, "v3", "projects.json")},
+		"GET /me/clients":  {data: loadTestData(t, "clients.json")},
+	})
+
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	groups, err := c.Summary(context.Background(), since, since.AddDate(0, 0, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+
+	g := groups[0]
+	if g.ProjectID != 1 || g.Project != "Project A" || g.Duration != time.Hour {
+		t.Errorf("bad group: %+v", g)
+	}
+
+	expected := []SummarySubGroup{
+		{"design", 30 * time.Minute},
+		{"review", 30 * time.Minute},
+	}
+	if !slices.Equal(g.SubGroups, expected) {
+		t.Errorf("expected %v, got %v", expected, g.SubGroups)
+	}
+
+	if g := groups[2]; g.ProjectID != 0 || g.Project != "" {
+		t.Errorf("expected no project, got %+v", g)
+	}
+}
+
+func TestDetailed(t *testing.T) {
+	checkPage := func(row any) func(t *testing.T, body map[string]any) {
+		return func(t *testing.T, body map[string]any) {
+			if body["first_row_number"] != row {
+				t.Errorf("expected first row %v, got %v", row, body["first_row_number"])
+			}
+		}
+	}
+
+	c := routeServer(t, map[string]route{
+		"POST /reports/workspace/1234/search/time_entries#1": {
+			data:   loadVersionData(t, "v3", "detailed_1.json"),
+			header: map[string]string{"X-Next-Row-Number": "2"},
+			check:  checkPage(nil),
+		},
+		"POST /reports/workspace/1234/search/time_entries#2": {
+			data:  loadVersionData(t, "v3", "detailed_2.json"),
+			check: checkPage(2.0),
+		},
+	})
+
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries, err := c.Detailed(context.Background(), since, since.AddDate(0, 0, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	e := entries[1]
+	start := time.Date(2023, 1, 3, 9, 0, 0, 0, time.UTC)
+	if e.ID != 12 || e.UserID != 7 || e.Username != "Bugs" || e.ProjectID != 1 || e.TaskID != 301 {
+		t.Errorf("bad entry: %+v", e)
+	}
+
+	if !e.Start.Equal(start) || !e.Stop.Equal(start.Add(15*time.Minute)) || e.Duration != 15*time.Minute {
+		t.Errorf("bad entry times: %+v", e)
+	}
+
+	if !slices.Equal(e.TagIDs, []int{201}) || !e.Billable || e.Description != "design" {
+		t.Errorf("bad entry details: %+v", e)
+	}
+
+	if e := entries[2]; e.ProjectID != 0 || e.Username != "Daffy" {
+		t.Errorf("bad entry: %+v", e)
+	}
+}
+
+func TestReportRetry(t *testing.T) {
+	c := routeServer(t, map[string]route{
+		"POST /reports/workspace/1234/weekly/time_entries#1": {status: http.StatusServiceUnavailable},
+		"POST /reports/workspace/1234/weekly/time_entries#2": {data: loadVersionData(t, "v3", "weekly.json")},
+	})
+	c.cfg.MaxRetries = 1
+	c.cfg.RetryWait = time.Millisecond
+
+	rows, err := c.Weekly(context.Background(), time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	c := routeServer(t, map[string]route{
+		"POST /reports/workspace/1234/weekly/time_entries": {data: loadVersionData(t, "v3", "weekly.json")},
+	})
+
+	rows, err := c.Weekly(context.Background(), time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	if r := rows[0]; r.ProjectID != 1 || r.Days[1] != 30*time.Minute || r.Total() != 90*time.Minute {
+		t.Errorf("bad row: %+v", r)
+	}
+
+	if r := rows[1]; r.Days[6] != 15*time.Minute || r.Total() != 2*time.Hour+15*time.Minute {
+		t.Errorf("bad row: %+v", r)
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultRetryWait    = 500 * time.Millisecond
+	defaultMaxRetryWait = 30 * time.Second
+)
+
+// idempotent returns true if it's safe to send method again
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// shouldRetry returns true if a call that failed with err should be retried,
+// safe is true if the call can be sent again (see idempotent)
+func (c *Client) shouldRetry(ctx context.Context, safe bool, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests:
+			// Request was not processed, safe to retry any method
+			return true
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return c.cfg.RetryAll || safe
+		}
+		return false
+	}
+
+	// Network errors
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return c.cfg.RetryAll || safe
+	}
+
+	return false
+}
+
+// backoff returns how long to wait before retry number attempt.
+// It's exponential backoff with jitter, unless the server sent Retry-After.
+// The wait is capped by MaxRetryWait in both cases.
+func (c *Client) backoff(attempt int, err error) time.Duration {
+	maxWait := c.cfg.MaxRetryWait
+	if maxWait == 0 {
+		maxWait = defaultMaxRetryWait
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return min(apiErr.RetryAfter, maxWait)
+	}
+
+	wait := c.cfg.RetryWait
+	if wait == 0 {
+		wait = defaultRetryWait
+	}
+
+	for i := 0; i < attempt && wait < maxWait; i++ {
+		wait *= 2
+	}
+	wait = min(wait, maxWait)
+
+	// Random wait between wait/2 and wait
+	half := wait / 2
+	return half + rand.N(half+1) // #nosec G404 - jitter does not need crypto random
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures calls with status
+func flakyServer(t *testing.T, failures int, status int, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if int(n) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			http.Error(w, "try again", status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"id": 456, "pid": 1, "start": "2023-01-01T14:30:45Z"}`)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func newRetryClient(t *testing.T, srv *httptest.Server, retries int) *Client {
+	t.Helper()
+
+	cfg := Config{
+		APIToken:     "api-key",
+		WorkspaceID:  1234,
+		Timeout:      time.Second,
+		BaseURL:      srv.URL,
+		MaxRetries:   retries,
+		RetryWait:    time.Millisecond,
+		MaxRetryWait: 5 * time.Millisecond,
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRetry(t *testing.T) {
+	srv, calls := flakyServer(t, 2, http.StatusServiceUnavailable, "")
+	c := newRetryClient(t, srv, 3)
+
+	timer, err := c.Timer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if timer == nil || timer.ID != 456 {
+		t.Fatalf("bad timer: %+v", timer)
+	}
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("expected 3 calls, got %d", n)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusBadGateway, "")
+	c := newRetryClient(t, srv, 2)
+
+	_, err := c.Timer(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected bad gateway error, got %v", err)
+	}
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("expected 3 calls, got %d", n)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusServiceUnavailable, "")
+	c := newRetryClient(t, srv, 3)
+
+	if _, err := c.Start(context.Background(), 1, time.Now(), StartOptions{}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 call, got %d", n)
+	}
+}
+
+func TestRetryRateLimited(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusTooManyRequests, "1")
+	c := newRetryClient(t, srv, 1)
+	c.cfg.MaxRetryWait = 2 * time.Second // Don't cap Retry-After
+
+	start := time.Now()
+	if _, err := c.Start(context.Background(), 1, time.Now(), StartOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := time.Since(start); d < time.Second {
+		t.Errorf("Retry-After not honored, retried after %v", d)
+	}
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+}
+
+func TestRetryNotFound(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusNotFound, "")
+	c := newRetryClient(t, srv, 3)
+
+	if _, err := c.Timer(context.Background()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 call, got %d", n)
+	}
+}
+
+func Test_backoff(t *testing.T) {
+	c := &Client{cfg: Config{RetryWait: 100 * time.Millisecond, MaxRetryWait: time.Second}}
+
+	testCases := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond, 200 * time.Millisecond},
+		{2, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", tc.attempt), func(t *testing.T) {
+			d := c.backoff(tc.attempt, errors.New("oops"))
+			if d < tc.min || d > tc.max {
+				t.Errorf("expected wait in [%v, %v], got %v", tc.min, tc.max, d)
+			}
+		})
+	}
+
+	apiErr := &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 300 * time.Millisecond}
+	if d := c.backoff(0, apiErr); d != 300*time.Millisecond {
+		t.Errorf("expected Retry-After wait, got %v", d)
+	}
+
+	apiErr.RetryAfter = time.Hour
+	if d := c.backoff(0, apiErr); d != time.Second {
+		t.Errorf("expected Retry-After capped at 1s, got %v", d)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(100, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 5 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 2 in burst, 3 more at 10ms interval
+	if d := time.Since(start); d < 25*time.Millisecond {
+		t.Errorf("limiter too fast: 5 calls in %v", d)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	l = newLimiter(0.001, 1)
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("first call should not wait: %v", err)
+	}
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Tag is a toggl workspace tag
+type Tag struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	WorkspaceID int    `json:"workspace_id"`
+}
+
+func (c *Client) tagsURL() string {
+	return fmt.Sprintf("%s/workspaces/%d/tags", c.baseURL, c.cfg.WorkspaceID)
+}
+
+// Tags returns the workspace tags
+func (c *Client) Tags(ctx context.Context) ([]Tag, error) {
+	var tags []Tag
+	if err := c.call(ctx, http.MethodGet, c.tagsURL(), nil, &tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// CreateTag creates a new tag
+func (c *Client) CreateTag(ctx context.Context, name string) (*Tag, error) {
+	if name == "" {
+		return nil, fmt.Errorf("create tag: empty name")
+	}
+
+	body, err := jsonBody(map[string]any{"name": name, "workspace_id": c.cfg.WorkspaceID})
+	if err != nil {
+		return nil, err
+	}
+
+	var tag Tag
+	if err := c.call(ctx, http.MethodPost, c.tagsURL(), body, &tag); err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// RenameTag renames tag id to name
+func (c *Client) RenameTag(ctx context.Context, id int, name string) (*Tag, error) {
+	if name == "" {
+		return nil, fmt.Errorf("rename tag: empty name")
+	}
+
+	body, err := jsonBody(map[string]any{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/%d", c.tagsURL(), id)
+	var tag Tag
+	if err := c.call(ctx, http.MethodPut, url, body, &tag); err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// DeleteTag deletes tag id
+func (c *Client) DeleteTag(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/%d", c.tagsURL(), id)
+	return c.call(ctx, http.MethodDelete, url, nil, nil)
+}
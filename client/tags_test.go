@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "tags.json"))
+
+	tags, err := c.Tags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "GET" || req.Path != "/workspaces/1234/tags" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	expected := []Tag{
+		{ID: 201, Name: "dev", WorkspaceID: 1234},
+		{ID: 202, Name: "meeting", WorkspaceID: 1234},
+	}
+	if !slices.Equal(tags, expected) {
+		t.Errorf("expected %+v, got %+v", expected, tags)
+	}
+}
+
+func TestCreateTag(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "tag.json"))
+
+	tag, err := c.CreateTag(context.Background(), "review")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "POST" || req.Path != "/workspaces/1234/tags" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	if req.Body["name"] != "review" || req.Body["workspace_id"] != 1234.0 {
+		t.Errorf("bad body: %v", req.Body)
+	}
+
+	if *tag != (Tag{ID: 203, Name: "review", WorkspaceID: 1234}) {
+		t.Errorf("bad tag: %+v", tag)
+	}
+
+	if _, err := c.CreateTag(context.Background(), ""); err == nil {
+		t.Error("expected error on empty name, got nil")
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "tag.json"))
+
+	if _, err := c.RenameTag(context.Background(), 203, "review"); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "PUT" || req.Path != "/workspaces/1234/tags/203" || req.Body["name"] != "review" {
+		t.Errorf("bad request: %+v", req)
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	c, req := recordServer(t, nil)
+
+	if err := c.DeleteTag(context.Background(), 203); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "DELETE" || req.Path != "/workspaces/1234/tags/203" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+}
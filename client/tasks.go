@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Task is a toggl project task
+type Task struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	ProjectID   int    `json:"project_id"`
+	WorkspaceID int    `json:"workspace_id"`
+	Active      bool   `json:"active"`
+}
+
+// Tasks returns the tasks of project projectID
+func (c *Client) Tasks(ctx context.Context, projectID int) ([]Task, error) {
+	url := fmt.Sprintf("%s/%d/tasks", c.projectsURL(), projectID)
+	var tasks []Task
+	if err := c.call(ctx, http.MethodGet, url, nil, &tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// TaskNames returns map of task ID -> name for the tasks of projects pids
+func (c *Client) TaskNames(ctx context.Context, pids []int) (map[int]string, error) {
+	names := make(map[int]string)
+	seen := make(map[int]bool)
+	for _, pid := range pids {
+		if pid == 0 || seen[pid] {
+			continue
+		}
+		seen[pid] = true
+
+		tasks, err := c.Tasks(ctx, pid)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tasks {
+			names[t.ID] = t.Name
+		}
+	}
+
+	return names, nil
+}
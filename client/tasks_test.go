@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestTasks(t *testing.T) {
+	c, req := recordServer(t, loadTestData(t, "tasks.json"))
+
+	tasks, err := c.Tasks(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != "GET" || req.Path != "/workspaces/1234/projects/1/tasks" {
+		t.Errorf("bad request: %s %s", req.Method, req.Path)
+	}
+
+	expected := []Task{
+		{ID: 301, Name: "backend", ProjectID: 1, WorkspaceID: 1, Active: true},
+		{ID: 302, Name: "frontend", ProjectID: 1, WorkspaceID: 1},
+	}
+	if !slices.Equal(tasks, expected) {
+		t.Errorf("expected %+v, got %+v", expected, tasks)
+	}
+}
+
+func TestTaskNames(t *testing.T) {
+	routes := map[string]route{
+		"GET /workspaces/1234/projects/1/tasks": {data: loadTestData(t, "tasks.json")},
+	}
+	c := routeServer(t, routes)
+
+	// 0 is no project, duplicates are fetched once (routeServer fails on unknown calls)
+	names, err := c.TaskNames(context.Background(), []int{1, 0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 2 || names[301] != "backend" || names[302] != "frontend" {
+		t.Errorf("bad names: %v", names)
+	}
+}
@@ -6,11 +6,26 @@ I scratched an itch, hope you'll find it useful.
 Usage
 
     $ toggl -h
-    usage: toggl start <project>|stop|status|projects|report <since>
-	    <project> - project name
-	    <since> - YYYY-MM-DD (default to start of today)
-      -version
-	    show version and exit
+    Usage: toggl [-format <format>] <command> [arguments]
+
+    The commands are:
+      add    add completed time entry
+      clients    show and manage workspace clients
+      continue    restart last entry
+      delete    delete time entry
+      edit    edit time entry
+      heatmap    show calendar of hours per day
+      log    list time entries
+      projects    show and manage workspace projects
+      report    print report
+      start    start timer
+      status    timer status
+      stop    stop timer
+      switch    stop timer and start another
+      tags    show and manage workspace tags
+      undo    undo last change
+      version    show version and exit
+    Use "toggl <command> -h" for more information about a command.
 
 
 You'll need a `~/.togglrc` with your API key and workspace id. See
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/tebeka/toggl/client"
+)
+
+const (
+	journalEnvKey = "TOGGL_JOURNAL"
+	// maxJournal is the maximal number of actions kept in the journal
+	maxJournal = 50
+)
+
+// change is a single time entry change made by the CLI.
+// Before is nil for created entries and After is nil for deleted ones.
+type change struct {
+	Before *client.TimeEntry `json:"before,omitempty"`
+	After  *client.TimeEntry `json:"after,omitempty"`
+}
+
+// action is a journal record of a mutating command
+type action struct {
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	Changes []change  `json:"changes"`
+}
+
+func journalFile() (string, error) {
+	if path := os.Getenv(journalEnvKey); len(path) > 0 {
+		return path, nil
+	}
+
+	user, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/.toggl_journal", user.HomeDir), nil
+}
+
+func loadJournal() ([]action, error) {
+	fname, err := journalFile()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(fname) // #nosec
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []action
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return nil, fmt.Errorf("%s: bad journal - %w", fname, err)
+	}
+	return actions, nil
+}
+
+func saveJournal(actions []action) error {
+	fname, err := journalFile()
+	if err != nil {
+		return err
+	}
+
+	if len(actions) > maxJournal {
+		actions = actions[len(actions)-maxJournal:]
+	}
+
+	data, err := json.MarshalIndent(actions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fname, data, 0600)
+}
+
+// record appends an action to the journal.
+// Failing to record should not fail the command, so errors are only printed.
+func record(cmd string, changes ...change) {
+	err := func() error {
+		actions, err := loadJournal()
+		if err != nil {
+			return err
+		}
+
+		a := action{
+			Command: cmd,
+			Time:    time.Now(),
+			Changes: changes,
+		}
+		return saveJournal(append(actions, a))
+	}()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: can't record %s in journal - %s\n", cmd, err)
+	}
+}
+
+// timerEntry returns the time entry of a running timer
+func timerEntry(t *client.Timer) client.TimeEntry {
+	return client.TimeEntry{
+		ID:          t.ID,
+		ProjectID:   t.Project,
+		TaskID:      t.Task,
+		Description: t.Description,
+		Tags:        t.Tags,
+		Billable:    t.Billable,
+		Start:       t.Start,
+		Duration:    -1,
+	}
+}
+
+// stoppedEntry returns a copy of e stopped after dur
+func stoppedEntry(e client.TimeEntry, dur time.Duration) client.TimeEntry {
+	stop := e.Start.Add(dur)
+	e.Stop = &stop
+	e.Duration = int64(dur.Seconds())
+	return e
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tebeka/toggl/client"
+)
+
+func TestJournal(t *testing.T) {
+	t.Setenv(journalEnvKey, filepath.Join(t.TempDir(), "journal"))
+
+	actions, err := loadJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 0 {
+		t.Fatalf("expected empty journal, got %v", actions)
+	}
+
+	for i := range maxJournal + 3 {
+		record(fmt.Sprintf("cmd-%d", i), change{After: &client.TimeEntry{ID: i}})
+	}
+
+	actions, err = loadJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actions) != maxJournal {
+		t.Fatalf("expected %d actions, got %d", maxJournal, len(actions))
+	}
+
+	last := actions[len(actions)-1]
+	expected := fmt.Sprintf("cmd-%d", maxJournal+2)
+	if last.Command != expected {
+		t.Errorf("expected last command %q, got %q", expected, last.Command)
+	}
+
+	if len(last.Changes) != 1 || last.Changes[0].Before != nil || last.Changes[0].After.ID != maxJournal+2 {
+		t.Errorf("bad changes: %+v", last.Changes)
+	}
+}
+
+func Test_stoppedEntry(t *testing.T) {
+	timer := client.Timer{
+		ID:      7,
+		Project: 3,
+		Start:   time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC),
+	}
+
+	running := timerEntry(&timer)
+	if !running.Running() {
+		t.Fatalf("expected running entry, got %+v", running)
+	}
+
+	stopped := stoppedEntry(running, time.Hour)
+	if stopped.Running() || stopped.Elapsed() != time.Hour || stopped.Duration != 3600 {
+		t.Errorf("bad stopped entry: %+v", stopped)
+	}
+
+	if !running.Running() {
+		t.Error("stoppedEntry changed original entry")
+	}
+}
+
+func Test_undoAction(t *testing.T) {
+	a := action{
+		Command: "switch",
+		Changes: []change{
+			{Before: &client.TimeEntry{ID: 1}, After: &client.TimeEntry{ID: 1}},
+			{After: &client.TimeEntry{ID: 2}},
+		},
+	}
+
+	// New entry (2) is reverted, reverting the stopped one (1) fails
+	var reverted []int
+	revert := func(ch change) (string, error) {
+		if ch.Before != nil {
+			return "", errors.New("server error")
+		}
+		reverted = append(reverted, ch.After.ID)
+		return "deleted", nil
+	}
+
+	pending, err := undoAction(io.Discard, a, revert)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if !slices.Equal(reverted, []int{2}) {
+		t.Errorf("expected reverted [2], got %v", reverted)
+	}
+
+	if len(pending) != 1 || pending[0].Before.ID != 1 {
+		t.Errorf("expected pending change of 1, got %+v", pending)
+	}
+
+	reverted = nil
+	a.Changes = a.Changes[1:]
+	pending, err = undoAction(io.Discard, a, revert)
+	if err != nil || pending != nil || !slices.Equal(reverted, []int{2}) {
+		t.Errorf("bad undo: %v %+v %v", err, pending, reverted)
+	}
+}
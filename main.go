@@ -1,19 +1,24 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"maps"
 	"os"
+	"os/signal"
 	"os/user"
 	"path"
 	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
+	"unicode/utf8"
 
 	"github.com/lithammer/fuzzysearch/fuzzy"
 
@@ -42,25 +47,49 @@ func configFile() (string, error) {
 	return fmt.Sprintf("%s/.togglrc", user.HomeDir), nil
 }
 
-func loadConfig() (client.Config, error) {
+// rcFile is the content of the configuration file
+type rcFile struct {
+	APIToken   string `json:"api_token"`
+	Workspace  string `json:"workspace"`
+	Timeout    string `json:"timeout"`
+	BaseURL    string `json:"base_url"`
+	ReportsURL string `json:"reports_url"`
+	Retries    *int   `json:"retries"`
+	// RetryWait and MaxRetryWait are durations (e.g. "500ms")
+	RetryWait    string  `json:"retry_wait"`
+	MaxRetryWait string  `json:"max_retry_wait"`
+	RetryAll     bool    `json:"retry_all"`
+	RateLimit    float64 `json:"rate_limit"`
+	RateBurst    int     `json:"rate_burst"`
+	Timezone     string  `json:"timezone"`
+	WeekStart    string  `json:"week_start"`
+	// Templates are named --template templates
+	Templates map[string]string `json:"templates"`
+}
+
+func readRC() (rcFile, error) {
 	fname, err := configFile()
 	if err != nil {
-		return client.Config{}, err
+		return rcFile{}, err
 	}
 
 	file, err := os.Open(fname) // #nosec
 	if err != nil {
-		return client.Config{}, err
+		return rcFile{}, err
 	}
 	defer file.Close() // #nosec
 
-	var cfg struct {
-		APIToken  string `json:"api_token"`
-		Workspace string `json:"workspace"`
-		Timeout   string `json:"timeout"`
+	var rc rcFile
+	if err := json.NewDecoder(file).Decode(&rc); err != nil {
+		return rcFile{}, err
 	}
 
-	if err := json.NewDecoder(file).Decode(&cfg); err != nil {
+	return rc, nil
+}
+
+func loadConfig() (client.Config, error) {
+	cfg, err := readRC()
+	if err != nil {
 		return client.Config{}, err
 	}
 
@@ -82,10 +111,33 @@ func loadConfig() (client.Config, error) {
 		return client.Config{}, fmt.Errorf("bad workspace ID: %w", err)
 	}
 
+	retries := 3
+	if cfg.Retries != nil {
+		retries = *cfg.Retries
+	}
+
+	retryWait, err := rcDuration("retry_wait", cfg.RetryWait)
+	if err != nil {
+		return client.Config{}, err
+	}
+
+	maxRetryWait, err := rcDuration("max_retry_wait", cfg.MaxRetryWait)
+	if err != nil {
+		return client.Config{}, err
+	}
+
 	c := client.Config{
-		APIToken:    cfg.APIToken,
-		WorkspaceID: int(wid),
-		Timeout:     timeout,
+		APIToken:     cfg.APIToken,
+		WorkspaceID:  int(wid),
+		Timeout:      timeout,
+		BaseURL:      cfg.BaseURL,
+		ReportsURL:   cfg.ReportsURL,
+		MaxRetries:   retries,
+		RetryWait:    retryWait,
+		MaxRetryWait: maxRetryWait,
+		RetryAll:     cfg.RetryAll,
+		RateLimit:    cfg.RateLimit,
+		RateBurst:    cfg.RateBurst,
 	}
 
 	if err := c.Validate(); err != nil {
@@ -95,6 +147,46 @@ func loadConfig() (client.Config, error) {
 	return c, nil
 }
 
+// rcDuration parses the duration configuration key, empty value is 0 (client default)
+func rcDuration(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("bad %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// loadTimeParser returns a time parser using the configured time zone
+func loadTimeParser() (timeParser, error) {
+	rc, err := readRC()
+	if err != nil {
+		return timeParser{}, err
+	}
+
+	loc := time.Local
+	if rc.Timezone != "" {
+		loc, err = time.LoadLocation(rc.Timezone)
+		if err != nil {
+			return timeParser{}, fmt.Errorf("bad timezone: %w", err)
+		}
+	}
+
+	p := newTimeParser(time.Now().In(loc))
+	if rc.WeekStart != "" {
+		wd, ok := weekdays[strings.ToLower(rc.WeekStart)]
+		if !ok {
+			return timeParser{}, fmt.Errorf("bad week_start: %q", rc.WeekStart)
+		}
+		p.weekStart = wd
+	}
+
+	return p, nil
+}
+
 func findProject(name string, prjs []client.Project) []client.Project {
 	name = strings.ToLower(name)
 	projects := make(map[string]client.Project)
@@ -121,11 +213,72 @@ func nameFromID(id int, prjs []client.Project) string {
 	return ""
 }
 
+// projectNames returns map of project ID -> project full name
+func projectNames(prjs []client.Project) map[int]string {
+	names := make(map[int]string, len(prjs))
+	for _, prj := range prjs {
+		names[prj.ID] = prj.FullName()
+	}
+	return names
+}
+
+// projectsByID returns map of project ID -> project
+func projectsByID(prjs []client.Project) map[int]client.Project {
+	byID := make(map[int]client.Project, len(prjs))
+	for _, prj := range prjs {
+		byID[prj.ID] = prj
+	}
+	return byID
+}
+
+// startOfDay returns midnight of t's day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// parseDateRange parses since & until date expressions to a time range.
+// since defaults to today and until (inclusive) defaults to since.
+func parseDateRange(p timeParser, since, until string) (time.Time, time.Time, error) {
+	start := startOfDay(p.now)
+	if since != "" {
+		t, err := p.parseDate(since)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = t
+	}
+
+	end := start
+	if until != "" {
+		t, err := p.parseDate(until)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = t
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("until (%s) is before since (%s)", until, since)
+	}
+
+	return start, end.AddDate(0, 0, 1), nil
+}
+
 func duration2str(dur time.Duration) string {
-	h, m, s := int(dur.Hours()), int(dur.Minutes())%60, int(dur.Seconds())%60
+	h, m, s := splitDuration(dur)
 	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
 }
 
+// duration2hm returns dur as HH:MM (seconds are truncated)
+func duration2hm(dur time.Duration) string {
+	h, m, _ := splitDuration(dur)
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+func splitDuration(dur time.Duration) (int, int, int) {
+	return int(dur.Hours()), int(dur.Minutes()) % 60, int(dur.Seconds()) % 60
+}
+
 func projectsStr(prjs []string) string {
 	s := make([]string, len(prjs))
 	copy(s, prjs)
@@ -153,47 +306,81 @@ func simpleHelp(fs *flag.FlagSet, cmd, desc string) {
 	}
 }
 
-func projectsCmd(args []string) error {
-	fs := flag.NewFlagSet("projects", flag.ExitOnError)
-	simpleHelp(fs, "projects", "List projects.")
-	if err := fs.Parse(args); err != nil {
-		return err
-	}
-	if fs.NArg() != 0 {
-		return fmt.Errorf("wrong number of arguments")
+// listFlag is a flag that can be repeated
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// entryFlags are time entry flags shared by several commands
+type entryFlags struct {
+	description string
+	tags        listFlag
+	billable    bool
+	noBillable  bool
+}
+
+func (f *entryFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.description, "d", "", "description")
+	fs.StringVar(&f.description, "description", "", "description")
+	fs.Var(&f.tags, "t", "tag (can be repeated)")
+	fs.Var(&f.tags, "tag", "tag (can be repeated)")
+	fs.BoolVar(&f.billable, "billable", false, "mark as billable")
+	fs.BoolVar(&f.noBillable, "no-billable", false, "mark as non billable")
+}
+
+// options returns start options from flags, billable is nil if not set (project default)
+func (f *entryFlags) options() (client.StartOptions, error) {
+	if f.billable && f.noBillable {
+		return client.StartOptions{}, fmt.Errorf("can't use both --billable and --no-billable")
 	}
 
-	c, err := newClient()
-	if err != nil {
-		return err
+	opts := client.StartOptions{
+		Description: f.description,
+		Tags:        f.tags,
 	}
 
-	prjs, err := c.Projects()
-	if err != nil {
-		return err
+	if f.billable || f.noBillable {
+		opts.Billable = &f.billable
 	}
 
-	names := make([]string, 0, len(prjs))
-	for _, prj := range prjs {
-		names = append(names, prj.FullName())
+	return opts, nil
+}
+
+// entryBillable returns the billable flag from opts, or the project default if not set
+func entryBillable(opts client.StartOptions, prj client.Project) bool {
+	if opts.Billable != nil {
+		return *opts.Billable
 	}
+	return prj.Billable
+}
 
-	cmp := func(i, j int) bool {
-		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+// checkNoTimer returns an error if there's a timer running
+func checkNoTimer(ctx context.Context, c *client.Client) error {
+	curTimer, err := c.Timer(ctx)
+	if err != nil {
+		return err
 	}
 
-	sort.Slice(names, cmp)
-	for _, name := range names {
-		fmt.Println(name)
+	if curTimer != nil {
+		return fmt.Errorf("there's a timer running")
 	}
 
 	return nil
 }
 
-func startCmd(args []string) error {
+func startCmd(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("start", flag.ExitOnError)
-	startTime := fs.String("time", "", "start time (HH:MM)")
-	simpleHelp(fs, "start [flags] <project>", "Start timer.")
+	startTime := fs.String("time", "", "start time (e.g. 14:00, 2pm, -15m)")
+	var ef entryFlags
+	ef.register(fs)
+	simpleHelp(fs, "start [flags] <project>[/<task>]", "Start timer.")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -203,13 +390,22 @@ func startCmd(args []string) error {
 		return fmt.Errorf("wrong number of arguments")
 	}
 
-	start := time.Now()
+	opts, err := ef.options()
+	if err != nil {
+		return err
+	}
+
+	p, err := loadTimeParser()
+	if err != nil {
+		return err
+	}
+
+	start := p.now
 	if *startTime != "" {
-		t, err := time.Parse("15:04", *startTime)
+		start, err = p.parse(*startTime)
 		if err != nil {
-			return fmt.Errorf("start: bad time (should be HH:MM) - %w", err)
+			return fmt.Errorf("start: %w", err)
 		}
-		start = time.Date(start.Year(), start.Month(), start.Day(), t.Hour(), t.Minute(), 0, 0, start.Location())
 	}
 
 	start = start.In(time.UTC)
@@ -219,84 +415,266 @@ func startCmd(args []string) error {
 		return err
 	}
 
-	curTimer, err := c.Timer()
+	if err := checkNoTimer(ctx, c); err != nil {
+		return err
+	}
+
+	prjs, err := c.Projects(ctx)
 	if err != nil {
 		return err
 	}
 
-	if curTimer != nil {
-		return fmt.Errorf("there's a timer running")
+	prj, task, err := matchProjectTask(ctx, c, fs.Arg(0), prjs)
+	if err != nil {
+		return err
+	}
+	opts.TaskID = task.ID
+
+	if opts.Tags, err = resolveTags(ctx, c, opts.Tags); err != nil {
+		return err
 	}
 
-	prjs, err := c.Projects()
+	fmt.Printf("Starting %s\n", taskTitle(prj.Name, task.Name))
+	e, err := c.Start(ctx, prj.ID, start, opts)
 	if err != nil {
 		return err
 	}
 
-	name := fs.Arg(0)
+	record("start", change{After: e})
+	return nil
+}
+
+// activeProjects returns the active (or archived if active is false) projects in prjs
+func activeProjects(prjs []client.Project, active bool) []client.Project {
+	var out []client.Project
+	for _, prj := range prjs {
+		if prj.Active == active {
+			out = append(out, prj)
+		}
+	}
+	return out
+}
+
+// matchProject returns the single active project matching name
+func matchProject(name string, prjs []client.Project) (client.Project, error) {
+	if len(findProject(name, activeProjects(prjs, true))) == 0 {
+		if archived := findProject(name, activeProjects(prjs, false)); len(archived) > 0 {
+			return client.Project{}, fmt.Errorf("no project match %s (%s is archived)", name, archived[0].Name)
+		}
+	}
+
+	return matchProjectIn(name, activeProjects(prjs, true))
+}
+
+// matchProjectIn returns the single project in prjs matching name
+func matchProjectIn(name string, prjs []client.Project) (client.Project, error) {
 	matches := findProject(name, prjs)
 	switch len(matches) {
 	case 0:
-		log.Fatalf("error: no project match %s", name)
+		return client.Project{}, fmt.Errorf("no project match %s", name)
 	case 1:
-	default:
-		names := make([]string, len(matches))
-		for i, p := range matches {
-			names[i] = p.Name
+		return matches[0], nil
+	}
+
+	// Exact match wins
+	for _, p := range matches {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
 		}
+	}
 
-		return fmt.Errorf("too many matches to %q: %s", name, projectsStr(names))
+	names := make([]string, len(matches))
+	for i, p := range matches {
+		names[i] = p.Name
 	}
 
-	fmt.Printf("Starting %s\n", matches[0].Name)
-	return c.Start(matches[0].ID, start)
+	return client.Project{}, fmt.Errorf("too many matches to %q: %s", name, projectsStr(names))
 }
 
-func stopCmd(args []string) error {
-	fs := flag.NewFlagSet("stop", flag.ExitOnError)
-	simpleHelp(fs, "stop", "Stop timer.")
+// matchTask returns the single active task matching name
+func matchTask(name string, tasks []client.Task) (client.Task, error) {
+	var active []client.Task
+	for _, t := range tasks {
+		if t.Active {
+			active = append(active, t)
+		}
+	}
+
+	return matchName("task", name, active, func(t client.Task) string { return t.Name })
+}
+
+// matchProjectTask matches "project" or "project/task", task is zero if not given
+func matchProjectTask(ctx context.Context, c *client.Client, name string, prjs []client.Project) (client.Project, client.Task, error) {
+	prjName, taskName, hasTask := strings.Cut(name, "/")
+	if hasTask {
+		// Project names may contain "/" (e.g. "CI/CD"), an exact name wins
+		for _, prj := range activeProjects(prjs, true) {
+			if strings.EqualFold(prj.Name, name) {
+				return prj, client.Task{}, nil
+			}
+		}
+	}
+
+	prj, err := matchProject(prjName, prjs)
+	if err != nil || !hasTask {
+		return prj, client.Task{}, err
+	}
+
+	tasks, err := c.Tasks(ctx, prj.ID)
+	if err != nil {
+		return client.Project{}, client.Task{}, err
+	}
+
+	task, err := matchTask(taskName, tasks)
+	if err != nil {
+		return client.Project{}, client.Task{}, fmt.Errorf("%s: %w", prj.Name, err)
+	}
+
+	return prj, task, nil
+}
+
+// taskTitle returns "project/task", or project if there's no task
+func taskTitle(project, task string) string {
+	if task == "" {
+		return project
+	}
+	return project + "/" + task
+}
+
+// taskNames returns map of task ID -> name for the tasks of entries
+func taskNames(ctx context.Context, c *client.Client, entries []client.TimeEntry) (map[int]string, error) {
+	var pids []int
+	for _, e := range entries {
+		if e.TaskID != 0 {
+			pids = append(pids, e.ProjectID)
+		}
+	}
+
+	if len(pids) == 0 {
+		return nil, nil
+	}
+	return c.TaskNames(ctx, pids)
+}
+
+func switchCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("switch", flag.ExitOnError)
+	var ef entryFlags
+	ef.register(fs)
+	simpleHelp(fs, "switch [flags] <project>[/<task>]", "Stop running timer and start a new one.")
+
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
-	if fs.NArg() != 0 {
+
+	if fs.NArg() != 1 {
 		return fmt.Errorf("wrong number of arguments")
 	}
 
-	c, err := newClient()
+	opts, err := ef.options()
 	if err != nil {
 		return err
 	}
 
-	curTimer, err := c.Timer()
+	c, err := newClient()
 	if err != nil {
 		return err
 	}
 
-	if curTimer == nil {
-		return fmt.Errorf("no timer running")
+	// Resolve project first so we don't stop the timer on a bad match
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return err
 	}
 
-	pid, dur, err := c.Stop(curTimer.ID)
+	prj, task, err := matchProjectTask(ctx, c, fs.Arg(0), prjs)
 	if err != nil {
 		return err
 	}
+	opts.TaskID = task.ID
+
+	if opts.Tags, err = resolveTags(ctx, c, opts.Tags); err != nil {
+		return err
+	}
 
-	prjs, err := c.Projects()
+	curTimer, err := c.Timer(ctx)
 	if err != nil {
 		return err
 	}
 
-	name := nameFromID(pid, prjs)
-	if name == "" {
-		name = unknownProject
+	// Old timer stops and new one starts at the same instant
+	now := time.Now().Truncate(time.Second)
+	var changes []change
+	if curTimer != nil {
+		out, err := stopAt(ctx, c, curTimer.ID, now)
+		if err != nil {
+			return err
+		}
+		dur := out.Elapsed()
+
+		before := timerEntry(curTimer)
+		after := stoppedEntry(before, dur)
+		changes = append(changes, change{Before: &before, After: &after})
+
+		name := nameFromID(out.ProjectID, prjs)
+		if name == "" {
+			name = unknownProject
+		}
+		fmt.Printf("%s: %s\n", name, duration2str(dur))
+	}
+
+	fmt.Printf("Starting %s\n", taskTitle(prj.Name, task.Name))
+	e, err := c.Start(ctx, prj.ID, now, opts)
+	if err != nil {
+		if len(changes) > 0 {
+			record("switch", changes...)
+		}
+		return err
 	}
-	fmt.Printf("%s: %s\n", name, duration2str(dur))
+
+	record("switch", append(changes, change{After: e})...)
 	return nil
 }
 
-func statusCmd(args []string) error {
-	fs := flag.NewFlagSet("status", flag.ExitOnError)
-	simpleHelp(fs, "status", "Show timer status.")
+// stopTime returns the stop time from --at or --ago flags, zero time if both are empty
+func stopTime(p timeParser, at, ago string, start time.Time) (time.Time, error) {
+	now := p.now
+	var stop time.Time
+	switch {
+	case at != "" && ago != "":
+		return time.Time{}, fmt.Errorf("can't use both --at and --ago")
+	case at != "":
+		var err error
+		stop, err = p.parseOn(at, start.In(now.Location()))
+		if err != nil {
+			return time.Time{}, err
+		}
+	case ago != "":
+		d, err := time.ParseDuration(ago)
+		if err != nil || d < 0 {
+			return time.Time{}, fmt.Errorf("bad duration %q (e.g. 20m)", ago)
+		}
+		stop = now.Add(-d)
+	default:
+		return time.Time{}, nil
+	}
+
+	if !stop.After(start) {
+		return time.Time{}, fmt.Errorf("stop time (%s) should be after timer start (%s)", stop.Format("2006-01-02 15:04"), start.In(now.Location()).Format("2006-01-02 15:04"))
+	}
+
+	if stop.After(now) {
+		return time.Time{}, fmt.Errorf("stop time (%s) is in the future", stop.Format("2006-01-02 15:04"))
+	}
+
+	return stop, nil
+}
+
+func stopCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stop", flag.ExitOnError)
+	at := fs.String("at", "", "stop time (e.g. 17:30, 5:30pm, yesterday 18:00)")
+	ago := fs.String("ago", "", "stop this long ago (e.g. 20m)")
+	registerOutput(fs)
+	simpleHelp(fs, "stop [flags]", "Stop timer.")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -309,102 +687,1110 @@ func statusCmd(args []string) error {
 		return err
 	}
 
-	t, err := c.Timer()
+	curTimer, err := c.Timer(ctx)
 	if err != nil {
 		return err
 	}
 
-	if t == nil {
-		return fmt.Errorf("no time is running")
+	if curTimer == nil {
+		return fmt.Errorf("no timer running")
 	}
 
-	dur := time.Since(t.Start)
+	p, err := loadTimeParser()
+	if err != nil {
+		return err
+	}
 
-	prjs, err := c.Projects()
+	stop, err := stopTime(p, *at, *ago, curTimer.Start)
 	if err != nil {
 		return err
 	}
 
-	name := nameFromID(t.Project, prjs)
+	var (
+		pid int
+		dur time.Duration
+	)
+	if stop.IsZero() {
+		pid, dur, err = c.Stop(ctx, curTimer.ID)
+		if err != nil {
+			return err
+		}
+	} else {
+		out, err := stopAt(ctx, c, curTimer.ID, stop)
+		if err != nil {
+			return err
+		}
+		pid, dur = out.ProjectID, out.Elapsed()
+	}
+
+	before := timerEntry(curTimer)
+	after := stoppedEntry(before, dur)
+	record("stop", change{Before: &before, After: &after})
+
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return err
+	}
+
+	name := nameFromID(pid, prjs)
 	if name == "" {
 		name = unknownProject
 	}
 
-	fmt.Printf("%s: %s\n", name, duration2str(dur))
-	return nil
+	after.ProjectID = pid
+	records := []entryRecord{newEntryRecord(after, projectsByID(prjs), nil, p.now.Location())}
+	return writeRecords(os.Stdout, records, func() error {
+		fmt.Printf("%s: %s\n", name, duration2str(dur))
+		return nil
+	})
 }
 
-func reportCmd(args []string) error {
-	fs := flag.NewFlagSet("report", flag.ExitOnError)
-	simpleHelp(fs, "report [date]", "Print report.")
+// stopAt stops time entry id at stop. It updates the entry instead of using the
+// stop endpoint, which stops at the server's time.
+func stopAt(ctx context.Context, c *client.Client, id int, stop time.Time) (*client.TimeEntry, error) {
+	e, err := c.TimeEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	e.Stop = &stop
+
+	return c.UpdateTimeEntry(ctx, *e)
+}
+
+func statusCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	registerOutput(fs)
+	simpleHelp(fs, "status [flags]", "Show timer status.")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
-	switch fs.NArg() {
-	case 0, 1:
-		// OK
-	default:
+	if fs.NArg() != 0 {
 		return fmt.Errorf("wrong number of arguments")
 	}
 
-	yday := time.Now().Add(-24 * time.Hour)
-	since := yday.Format("2006-01-02")
-	if fs.NArg() == 1 {
-		since = fs.Arg(0)
-		if _, err := time.Parse("2006-01-02", since); err != nil {
-			return fmt.Errorf("date format should be YYYY-MM-DD (got %q)", since)
-		}
-	}
-
 	c, err := newClient()
 	if err != nil {
 		return err
 	}
 
-	reps, err := c.Report(since)
+	t, err := c.Timer(ctx)
 	if err != nil {
-		log.Fatalf("error: can't get report: %s", err)
+		return err
 	}
 
-	for _, r := range reps {
-		fmt.Printf("%s: %s\n", r.Project, r.Duration)
+	if t == nil {
+		return fmt.Errorf("no time is running")
 	}
 
-	return nil
-}
+	dur := time.Since(t.Start)
 
-func versionCmd(args []string) error {
-	fs := flag.NewFlagSet("version", flag.ExitOnError)
-	simpleHelp(fs, "version", "Show version and exit.")
-	if err := fs.Parse(args); err != nil {
+	prjs, err := c.Projects(ctx)
+	if err != nil {
 		return err
 	}
-	if fs.NArg() != 0 {
-		return fmt.Errorf("wrong number of arguments")
+
+	name := nameFromID(t.Project, prjs)
+	if name == "" {
+		name = unknownProject
 	}
 
-	fmt.Printf("%s version %s\n", path.Base(os.Args[0]), version)
-	return nil
-}
+	e := timerEntry(t)
+	tasks, err := taskNames(ctx, c, []client.TimeEntry{e})
+	if err != nil {
+		return err
+	}
+	name = taskTitle(name, tasks[t.Task])
+
+	p, err := loadTimeParser()
+	if err != nil {
+		return err
+	}
+
+	records := []entryRecord{newEntryRecord(e, projectsByID(prjs), tasks, p.now.Location())}
+	return writeRecords(os.Stdout, records, func() error {
+		fmt.Printf("%s: %s%s\n", name, duration2str(dur), timerDetails(t))
+		return nil
+	})
+}
+
+// timerDetails returns description, tags & billable of t (with leading space)
+func timerDetails(t *client.Timer) string {
+	s := entryDetails(t.Description, t.Tags)
+	if t.Billable {
+		s += " $"
+	}
+	return s
+}
+
+// entryDetails returns description & tags (with leading space)
+func entryDetails(description string, tags []string) string {
+	var b strings.Builder
+	if description != "" {
+		fmt.Fprintf(&b, " %s", description)
+	}
+
+	if len(tags) > 0 {
+		fmt.Fprintf(&b, " %s", tagsStr(tags))
+	}
+
+	return b.String()
+}
+
+// reportPeriod is a report period flag
+type reportPeriod struct {
+	name string
+	desc string
+	// span returns the period inclusive start and end days
+	span func(p timeParser, today time.Time) (time.Time, time.Time)
+}
+
+var reportPeriods = []reportPeriod{
+	{"today", "report today", func(p timeParser, today time.Time) (time.Time, time.Time) {
+		return today, today
+	}},
+	{"week", "report this week", func(p timeParser, today time.Time) (time.Time, time.Time) {
+		return p.startOfWeek(today), today
+	}},
+	{"last-week", "report last week", func(p timeParser, today time.Time) (time.Time, time.Time) {
+		start := p.startOfWeek(today).AddDate(0, 0, -7)
+		return start, start.AddDate(0, 0, 6)
+	}},
+	{"month", "report this month", func(p timeParser, today time.Time) (time.Time, time.Time) {
+		return today.AddDate(0, 0, 1-today.Day()), today
+	}},
+	{"last-month", "report last month", func(p timeParser, today time.Time) (time.Time, time.Time) {
+		end := today.AddDate(0, 0, -today.Day())
+		return end.AddDate(0, 0, 1-end.Day()), end
+	}},
+	{"ytd", "report year to date", func(p timeParser, today time.Time) (time.Time, time.Time) {
+		return today.AddDate(0, 0, 1-today.YearDay()), today
+	}},
+}
+
+// reportRange returns report inclusive start and end days from arguments, default is today
+func reportRange(p timeParser, since, until string, periods []string) (time.Time, time.Time, error) {
+	today := startOfDay(p.now)
+
+	switch {
+	case len(periods) > 1:
+		return time.Time{}, time.Time{}, fmt.Errorf("can't use more than one of --%s", strings.Join(periods, ", --"))
+	case len(periods) == 1:
+		if since != "" || until != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("can't use --%s with dates", periods[0])
+		}
+
+		for _, rp := range reportPeriods {
+			if rp.name == periods[0] {
+				start, end := rp.span(p, today)
+				return start, end, nil
+			}
+		}
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period: %q", periods[0])
+	}
+
+	start, end, err := parseDateRange(p, since, until)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if until == "" {
+		end = today.AddDate(0, 0, 1)
+		if !end.After(start) {
+			return time.Time{}, time.Time{}, fmt.Errorf("since (%s) is in the future", since)
+		}
+	}
+
+	return start, end.AddDate(0, 0, -1), nil
+}
+
+func reportCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	until := fs.String("until", "", "end date, inclusive (default today)")
+	groupBy := fs.String("group-by", "", "group by project, client, task, tag, description, user or day")
+	subGroupBy := fs.String("subgroup-by", "", "sub group groups by project, client, task, tag, description, user or day")
+	sortBy := fs.String("sort", "duration", "sort by duration, project or client")
+	decimal := fs.Int("decimal", 2, "decimal places of hours")
+	chart := fs.Bool("chart", false, "show bar chart")
+	registerOutput(fs)
+	periods := make(map[string]*bool)
+	for _, rp := range reportPeriods {
+		periods[rp.name] = fs.Bool(rp.name, false, rp.desc)
+	}
+	simpleHelp(fs, "report [flags] [since]", "Print report (since defaults to today).")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch fs.NArg() {
+	case 0, 1:
+		// OK
+	default:
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	p, err := loadTimeParser()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, rp := range reportPeriods {
+		if *periods[rp.name] {
+			names = append(names, rp.name)
+		}
+	}
+
+	since, end, err := reportRange(p, fs.Arg(0), *until, names)
+	if err != nil {
+		return err
+	}
+
+	group, subGroup, err := reportGroups(*groupBy, *subGroupBy)
+	if err != nil {
+		return err
+	}
+
+	if *decimal < 0 {
+		return fmt.Errorf("bad --decimal: %d", *decimal)
+	}
+
+	if *chart && subGroup != "" {
+		return fmt.Errorf("can't use --chart with --subgroup-by")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	if group != "" {
+		groups, err := c.GroupedReport(ctx, since, end, group, subGroup)
+		if err != nil {
+			return fmt.Errorf("can't get report: %w", err)
+		}
+
+		return writeRecords(os.Stdout, groupRecords(groups), func() error {
+			if *chart {
+				rows := make([]chartRow, len(groups))
+				for i, g := range groups {
+					rows[i] = chartRow{g.Title, g.Duration}
+				}
+				return printChart(os.Stdout, rows, isUTF8())
+			}
+
+			printGroups(os.Stdout, groups, "")
+			return nil
+		})
+	}
+
+	reps, err := c.Report(ctx, since, end)
+	if err != nil {
+		return fmt.Errorf("can't get report: %w", err)
+	}
+
+	if err := sortReports(reps, *sortBy); err != nil {
+		return err
+	}
+
+	records := make([]reportRecord, len(reps))
+	for i, r := range reps {
+		records[i] = reportRecord{r.Project, r.Client, seconds(r.Duration)}
+	}
+
+	return writeRecords(os.Stdout, records, func() error {
+		if *chart {
+			rows := make([]chartRow, len(reps))
+			for i, r := range reps {
+				rows[i] = chartRow{reportTitle(r), r.Duration}
+			}
+			return printChart(os.Stdout, rows, isUTF8())
+		}
+
+		return printReport(os.Stdout, reps, *decimal)
+	})
+}
+
+// sortReports sorts reports by duration (longest first), project or client
+func sortReports(reps []client.Report, by string) error {
+	var less func(a, b client.Report) bool
+	switch by {
+	case "duration":
+		less = func(a, b client.Report) bool {
+			return a.Duration > b.Duration
+		}
+	case "project":
+		less = func(a, b client.Report) bool {
+			return strings.ToLower(a.Project) < strings.ToLower(b.Project)
+		}
+	case "client":
+		less = func(a, b client.Report) bool {
+			return strings.ToLower(a.Client) < strings.ToLower(b.Client)
+		}
+	default:
+		return fmt.Errorf("unknown sort %q (should be one of duration, project, client)", by)
+	}
+
+	sort.SliceStable(reps, func(i, j int) bool {
+		if less(reps[i], reps[j]) {
+			return true
+		}
+		if less(reps[j], reps[i]) {
+			return false
+		}
+		// Stable order for ties
+		return reps[i].Project < reps[j].Project
+	})
+	return nil
+}
+
+// reportTitle returns the project name of r
+func reportTitle(r client.Report) string {
+	if r.Project == "" {
+		return "(no project)"
+	}
+	return r.Project
+}
+
+// printReport prints reports as a table with a total row, hours have decimal places
+func printReport(w io.Writer, reps []client.Report, decimal int) error {
+	var total time.Duration
+	for _, r := range reps {
+		total += r.Duration
+	}
+
+	percent := func(d time.Duration) float64 {
+		if total == 0 {
+			return 0
+		}
+		return float64(d) / float64(total) * 100
+	}
+
+	rows := [][]string{{"PROJECT", "CLIENT", "TIME", "HOURS", "%"}}
+	row := func(project, client string, d time.Duration) {
+		hours := fmt.Sprintf("%.*f", decimal, d.Hours())
+		rows = append(rows, []string{project, client, duration2hm(d), hours, fmt.Sprintf("%.1f%%", percent(d))})
+	}
+
+	for _, r := range reps {
+		row(reportTitle(r), r.Client, r.Duration)
+	}
+	row("TOTAL", "", total)
+
+	return printTable(w, rows, 2)
+}
+
+// printTable prints rows aligned, the first left columns are aligned left and the rest right
+func printTable(w io.Writer, rows [][]string, left int) error {
+	var widths []int
+	for _, r := range rows {
+		for i, cell := range r {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	for _, r := range rows {
+		cells := make([]string, len(r))
+		for i, cell := range r {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if i < left {
+				cells[i] = cell + pad
+			} else {
+				cells[i] = pad + cell
+			}
+		}
+
+		if _, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reportGroups parses the report --group-by and --subgroup-by flags
+func reportGroups(groupBy, subGroupBy string) (client.GroupBy, client.GroupBy, error) {
+	if groupBy == "" {
+		if subGroupBy != "" {
+			return "", "", fmt.Errorf("--subgroup-by requires --group-by")
+		}
+		return "", "", nil
+	}
+
+	group, err := client.ParseGroupBy(groupBy)
+	if err != nil {
+		return "", "", err
+	}
+
+	if subGroupBy == "" {
+		return group, "", nil
+	}
+
+	subGroup, err := client.ParseGroupBy(subGroupBy)
+	if err != nil {
+		return "", "", err
+	}
+
+	if subGroup == group {
+		return "", "", fmt.Errorf("can't group and sub group by %s", group)
+	}
+
+	return group, subGroup, nil
+}
+
+// printGroups prints report groups as a tree, sub groups are indented under their group
+func printGroups(w io.Writer, groups []client.ReportGroup, indent string) {
+	for _, g := range groups {
+		fmt.Fprintf(w, "%s%s: %s\n", indent, g.Title, duration2str(g.Duration))
+		printGroups(w, g.SubGroups, indent+"  ")
+	}
+}
+
+// fetchEntries returns time entries in [since, until) sorted by start time, newest first
+func fetchEntries(ctx context.Context, c *client.Client, since, until time.Time) ([]client.TimeEntry, error) {
+	entries, err := c.TimeEntries(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.After(entries[j].Start)
+	})
+	return entries, nil
+}
+
+func tagsStr(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%s]", strings.Join(tags, ", "))
+}
+
+func printEntries(entries []client.TimeEntry, names, tasks map[int]string, loc *time.Location, withDate bool) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, e := range entries {
+		start := e.Start.In(loc)
+		times := start.Format("15:04") + "-"
+		if e.Stop != nil {
+			times += e.Stop.In(loc).Format("15:04")
+		} else {
+			times += "now"
+		}
+		if withDate {
+			times = fmt.Sprintf("%s %s", start.Format("2006-01-02"), times)
+		}
+
+		name := names[e.ProjectID]
+		if name == "" {
+			name = "-"
+		}
+		name = taskTitle(name, tasks[e.TaskID])
+
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\n", i+1, e.ID, times, duration2str(e.Elapsed()), name, e.Description, tagsStr(e.Tags))
+	}
+	return w.Flush()
+}
+
+func logCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("log", flag.ExitOnError)
+	since := fs.String("since", "", "start date (e.g. yesterday, monday, 2006-01-02), default today")
+	until := fs.String("until", "", "end date, inclusive, default since")
+	registerOutput(fs)
+	simpleHelp(fs, "log [flags]", "List time entries.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	p, err := loadTimeParser()
+	if err != nil {
+		return err
+	}
+
+	start, end, err := parseDateRange(p, *since, *until)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	entries, err := fetchEntries(ctx, c, start, end)
+	if err != nil {
+		return err
+	}
+
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := taskNames(ctx, c, entries)
+	if err != nil {
+		return err
+	}
+
+	byID, loc := projectsByID(prjs), p.now.Location()
+	records := make([]entryRecord, len(entries))
+	for i, e := range entries {
+		records[i] = newEntryRecord(e, byID, tasks, loc)
+	}
+
+	withDate := !end.Equal(start.AddDate(0, 0, 1))
+	return writeRecords(os.Stdout, records, func() error {
+		return printEntries(entries, projectNames(prjs), tasks, loc, withDate)
+	})
+}
+
+// lastStopped returns the most recent stopped entry in entries (sorted newest first)
+func lastStopped(entries []client.TimeEntry) (client.TimeEntry, bool) {
+	for _, e := range entries {
+		if !e.Running() {
+			return e, true
+		}
+	}
+
+	return client.TimeEntry{}, false
+}
+
+func continueCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("continue", flag.ExitOnError)
+	since := fs.String("since", "", "start date (e.g. yesterday, monday, 2006-01-02), default today")
+	until := fs.String("until", "", "end date, inclusive, default since")
+	simpleHelp(fs, "continue [flags] [N]", "Restart the last stopped entry, or entry number N from log.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	n := 0
+	switch fs.NArg() {
+	case 0:
+		// Last stopped entry
+	case 1:
+		var err error
+		n, err = strconv.Atoi(fs.Arg(0))
+		if err != nil || n < 1 {
+			return fmt.Errorf("bad entry number: %q", fs.Arg(0))
+		}
+	default:
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	p, err := loadTimeParser()
+	if err != nil {
+		return err
+	}
+
+	now := p.now
+	start, end, err := parseDateRange(p, *since, *until)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	if err := checkNoTimer(ctx, c); err != nil {
+		return err
+	}
+
+	entries, err := fetchEntries(ctx, c, start, end)
+	if err != nil {
+		return err
+	}
+
+	var entry client.TimeEntry
+	if n > 0 {
+		if n > len(entries) {
+			return fmt.Errorf("no entry %d (found %d entries)", n, len(entries))
+		}
+		entry = entries[n-1]
+	} else {
+		var ok bool
+		entry, ok = lastStopped(entries)
+		if !ok && *since == "" {
+			// Nothing today, look at the last week
+			entries, err = fetchEntries(ctx, c, start.AddDate(0, 0, -7), end)
+			if err != nil {
+				return err
+			}
+			entry, ok = lastStopped(entries)
+		}
+
+		if !ok {
+			return fmt.Errorf("no stopped entry found")
+		}
+	}
+
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return err
+	}
+
+	name := projectNames(prjs)[entry.ProjectID]
+	if name == "" {
+		name = unknownProject
+	}
+
+	fmt.Printf("Continuing %s%s\n", name, entryDetails(entry.Description, entry.Tags))
+	e, err := c.Start(ctx, entry.ProjectID, now, continueOptions(entry))
+	if err != nil {
+		return err
+	}
+
+	record("continue", change{After: e})
+	return nil
+}
+
+// continueOptions returns start options for a new entry continuing e
+func continueOptions(e client.TimeEntry) client.StartOptions {
+	return client.StartOptions{
+		Description: e.Description,
+		Tags:        e.Tags,
+		TaskID:      e.TaskID,
+		Billable:    &e.Billable,
+	}
+}
+
+// parseEnd parses end time (e.g. 10:30) or duration (e.g. 45m) from start
+func parseEnd(p timeParser, s string, start time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("bad duration %q", s)
+		}
+		return start.Add(d), nil
+	}
+
+	end, err := p.parseOn(s, start)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad end %q (should be time or duration)", s)
+	}
+
+	if !end.After(start) {
+		return time.Time{}, fmt.Errorf("end (%s) should be after start (%s)", s, start.Format("15:04"))
+	}
+
+	return end, nil
+}
+
+// overlapping returns entries that overlap [start, end)
+func overlapping(entries []client.TimeEntry, start, end time.Time) []client.TimeEntry {
+	var out []client.TimeEntry
+	for _, e := range entries {
+		eEnd := e.Start.Add(e.Elapsed())
+		if e.Start.Before(end) && eEnd.After(start) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func addCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	var ef entryFlags
+	ef.register(fs)
+	force := fs.Bool("f", false, "add even if overlapping existing entries")
+	fs.BoolVar(force, "force", false, "add even if overlapping existing entries")
+	simpleHelp(fs, "add [flags] <project>[/<task>] [day] <start> <end|duration>", "Add a completed time entry.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, err := loadTimeParser()
+	if err != nil {
+		return err
+	}
+
+	day := p.now
+	var startStr, endStr string
+	switch fs.NArg() {
+	case 3:
+		startStr, endStr = fs.Arg(1), fs.Arg(2)
+	case 4:
+		day, err = p.parseDate(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		startStr, endStr = fs.Arg(2), fs.Arg(3)
+	default:
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	start, err := p.parseOn(startStr, day)
+	if err != nil {
+		return err
+	}
+
+	end, err := parseEnd(p, endStr, start)
+	if err != nil {
+		return err
+	}
+
+	opts, err := ef.options()
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return err
+	}
+
+	prj, task, err := matchProjectTask(ctx, c, fs.Arg(0), prjs)
+	if err != nil {
+		return err
+	}
+
+	if opts.Tags, err = resolveTags(ctx, c, opts.Tags); err != nil {
+		return err
+	}
+
+	if !*force {
+		// Entries starting a day before might run into our time range
+		entries, err := fetchEntries(ctx, c, start.AddDate(0, 0, -1), end)
+		if err != nil {
+			return err
+		}
+
+		if over := overlapping(entries, start, end); len(over) > 0 {
+			names := projectNames(prjs)
+			ids := make([]string, len(over))
+			for i, e := range over {
+				ids[i] = fmt.Sprintf("%d (%s %s)", e.ID, names[e.ProjectID], e.Start.In(p.now.Location()).Format("15:04"))
+			}
+			return fmt.Errorf("overlaps %s (use -force to add anyway)", strings.Join(ids, ", "))
+		}
+	}
+
+	e := client.TimeEntry{
+		ProjectID:   prj.ID,
+		TaskID:      task.ID,
+		Description: opts.Description,
+		Tags:        opts.Tags,
+		Billable:    entryBillable(opts, prj),
+		Start:       start,
+		Stop:        &end,
+	}
+
+	out, err := c.CreateTimeEntry(ctx, e)
+	if err != nil {
+		return err
+	}
+	record("add", change{After: out})
+
+	fmt.Printf("Added %s: %s-%s (%s)\n", taskTitle(prj.Name, task.Name), start.Format("15:04"), end.Format("15:04"), duration2str(end.Sub(start)))
+	return nil
+}
+
+// setFlags returns the names of flags set in the command line
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// entryTarget returns the time entry with ID in arg, or the running one if arg is empty
+func entryTarget(ctx context.Context, c *client.Client, arg string) (*client.TimeEntry, error) {
+	if arg == "" {
+		t, err := c.Timer(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if t == nil {
+			return nil, fmt.Errorf("no timer running")
+		}
+		arg = strconv.Itoa(t.ID)
+	}
+
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, fmt.Errorf("bad time entry ID: %q", arg)
+	}
+
+	return c.TimeEntry(ctx, id)
+}
+
+func editCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ExitOnError)
+	var ef entryFlags
+	ef.register(fs)
+	project := fs.String("p", "", "project[/task]")
+	fs.StringVar(project, "project", "", "project[/task]")
+	startTime := fs.String("start", "", "start time (e.g. 09:00, 9am, 2006-01-02 15:04)")
+	stopTime := fs.String("stop", "", "stop time (e.g. 17:30, 5:30pm, 2006-01-02 15:04)")
+	simpleHelp(fs, "edit [flags] [id]", "Edit time entry (default to running timer).")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 1 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	set := setFlags(fs)
+	if len(set) == 0 {
+		return fmt.Errorf("nothing to edit")
+	}
+
+	p, err := loadTimeParser()
+	if err != nil {
+		return err
+	}
+
+	opts, err := ef.options()
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	e, err := entryTarget(ctx, c, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	before := *e
+
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *project != "" {
+		// A task belongs to its project, so it's replaced (or cleared) as well
+		prj, task, err := matchProjectTask(ctx, c, *project, prjs)
+		if err != nil {
+			return err
+		}
+		e.ProjectID, e.TaskID = prj.ID, task.ID
+	}
+
+	if set["d"] || set["description"] {
+		e.Description = opts.Description
+	}
+
+	if set["t"] || set["tag"] {
+		if e.Tags, err = resolveTags(ctx, c, opts.Tags); err != nil {
+			return err
+		}
+	}
+
+	if opts.Billable != nil {
+		e.Billable = *opts.Billable
+	}
+
+	loc := p.now.Location()
+	day := e.Start.In(loc)
+	if *startTime != "" {
+		if e.Start, err = p.parseOn(*startTime, day); err != nil {
+			return err
+		}
+	}
+
+	if *stopTime != "" {
+		stop, err := p.parseOn(*stopTime, day)
+		if err != nil {
+			return err
+		}
+		e.Stop = &stop
+	}
+
+	if e.Stop != nil && !e.Stop.After(e.Start) {
+		return fmt.Errorf("stop (%s) should be after start (%s)", e.Stop.In(loc).Format("15:04"), e.Start.In(loc).Format("15:04"))
+	}
+
+	out, err := c.UpdateTimeEntry(ctx, *e)
+	if err != nil {
+		return err
+	}
+	record("edit", change{Before: &before, After: out})
+
+	name := projectNames(prjs)[out.ProjectID]
+	if name == "" {
+		name = unknownProject
+	}
+	fmt.Printf("Updated %d: %s %s%s\n", out.ID, name, duration2str(out.Elapsed()), entryDetails(out.Description, out.Tags))
+	return nil
+}
+
+func deleteCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	simpleHelp(fs, "delete <id>", "Delete time entry.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	e, err := entryTarget(ctx, c, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err := c.DeleteTimeEntry(ctx, e.ID); err != nil {
+		return err
+	}
+	record("delete", change{Before: e})
+
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return err
+	}
+
+	name := projectNames(prjs)[e.ProjectID]
+	if name == "" {
+		name = unknownProject
+	}
+	fmt.Printf("Deleted %d: %s %s%s\n", e.ID, name, duration2str(e.Elapsed()), entryDetails(e.Description, e.Tags))
+	return nil
+}
+
+// revert undoes a single change
+func revert(ctx context.Context, c *client.Client, ch change) (string, error) {
+	switch {
+	case ch.Before == nil && ch.After != nil:
+		if err := c.DeleteTimeEntry(ctx, ch.After.ID); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("deleted %d", ch.After.ID), nil
+	case ch.Before != nil && ch.After == nil:
+		e, err := c.CreateTimeEntry(ctx, *ch.Before)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("restored %d as %d", ch.Before.ID, e.ID), nil
+	case ch.Before != nil:
+		if _, err := c.UpdateTimeEntry(ctx, *ch.Before); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("reverted %d", ch.Before.ID), nil
+	}
+
+	return "", fmt.Errorf("empty change")
+}
+
+func undoCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("undo", flag.ExitOnError)
+	simpleHelp(fs, "undo", "Undo last start, stop, switch, continue, add, edit or delete.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	actions, err := loadJournal()
+	if err != nil {
+		return err
+	}
+
+	if len(actions) == 0 {
+		return fmt.Errorf("nothing to undo")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	last := actions[len(actions)-1]
+	pending, err := undoAction(os.Stdout, last, func(ch change) (string, error) {
+		return revert(ctx, c, ch)
+	})
+	if err != nil {
+		// Keep only the changes that weren't reverted, so the next undo won't replay the others
+		last.Changes = pending
+		actions[len(actions)-1] = last
+		if jerr := saveJournal(actions); jerr != nil {
+			return errors.Join(err, jerr)
+		}
+		return err
+	}
+
+	return saveJournal(actions[:len(actions)-1])
+}
+
+// undoAction reverts the changes of a, last first, printing what was done to w.
+// On error it returns the changes that are still pending.
+func undoAction(w io.Writer, a action, revert func(change) (string, error)) ([]change, error) {
+	for i := len(a.Changes) - 1; i >= 0; i-- {
+		msg, err := revert(a.Changes[i])
+		if err != nil {
+			return a.Changes[:i+1], fmt.Errorf("undo %s: %w", a.Command, err)
+		}
+		fmt.Fprintf(w, "%s: %s\n", a.Command, msg)
+	}
+
+	return nil, nil
+}
+
+func versionCmd(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	simpleHelp(fs, "version", "Show version and exit.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	fmt.Printf("%s version %s\n", path.Base(os.Args[0]), version)
+	return nil
+}
 
 type cmd struct {
 	name string
 	desc string
-	fn   func([]string) error
+	fn   func(context.Context, []string) error
 }
 
 var cmds = []cmd{
-	{"projects", "show workspace projects", projectsCmd},
+	{"add", "add completed time entry", addCmd},
+	{"clients", "show and manage workspace clients", clientsCmd},
+	{"continue", "restart last entry", continueCmd},
+	{"delete", "delete time entry", deleteCmd},
+	{"edit", "edit time entry", editCmd},
+	{"heatmap", "show calendar of hours per day", heatmapCmd},
+	{"log", "list time entries", logCmd},
+	{"projects", "show and manage workspace projects", projectsCmd},
 	{"report", "print report", reportCmd},
 	{"start", "start timer", startCmd},
 	{"status", "timer status", statusCmd},
 	{"stop", "stop timer", stopCmd},
+	{"switch", "stop timer and start another", switchCmd},
+	{"tags", "show and manage workspace tags", tagsCmd},
+	{"undo", "undo last change", undoCmd},
 	{"version", "show version and exit", versionCmd},
 }
 
 func printUsage() {
 	progName := path.Base(os.Args[0])
-	fmt.Fprintf(os.Stderr, "Usage: %s <command> [arguments]\n\n", progName)
+	fmt.Fprintf(os.Stderr, "Usage: %s [-format <format>] <command> [arguments]\n\n", progName)
 	fmt.Fprintf(os.Stderr, "The commands are:\n")
 	for _, cmd := range cmds {
 		fmt.Fprintf(os.Stderr, "  %s    %s\n", cmd.name, cmd.desc)
@@ -412,6 +1798,32 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "Use \"%s <command> -h\" for more information about a command.\n", progName)
 }
 
+// errorMessage returns a user friendly message for err
+func errorMessage(err error) string {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		return err.Error()
+	}
+
+	switch {
+	case errors.Is(err, client.ErrUnauthorized):
+		fname, _ := configFile()
+		return fmt.Sprintf("toggl rejected your credentials - check api_token and workspace in %s", fname)
+	case errors.Is(err, client.ErrNotFound):
+		return fmt.Sprintf("not found - %s %s", apiErr.Method, apiErr.URL)
+	case errors.Is(err, client.ErrRateLimited):
+		if apiErr.RetryAfter > 0 {
+			return fmt.Sprintf("rate limited by toggl - try again in %s", apiErr.RetryAfter)
+		}
+		return "rate limited by toggl - try again later"
+	}
+
+	if apiErr.Message != "" {
+		return fmt.Sprintf("toggl error (%s) - %s", apiErr.Status, apiErr.Message)
+	}
+	return err.Error()
+}
+
 func findCmd(name string) cmd {
 	for _, c := range cmds {
 		if c.name == name {
@@ -428,14 +1840,21 @@ func main() {
 		os.Exit(1)
 	}
 
-	cmdName := os.Args[1]
-	switch cmdName {
-	case "-h", "--help":
+	// Global flags come before the command name
+	globals := flag.NewFlagSet(exeName(), flag.ExitOnError)
+	registerOutput(globals)
+	globals.Usage = printUsage
+	if err := globals.Parse(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if globals.NArg() == 0 {
 		printUsage()
-		os.Exit(0)
+		os.Exit(1)
 	}
 
-	args := os.Args[2:]
+	cmdName, args := globals.Arg(0), globals.Args()[1:]
 
 	cmd := findCmd(cmdName)
 	if cmd.fn == nil {
@@ -443,8 +1862,11 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := cmd.fn(args); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := cmd.fn(ctx, args)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", errorMessage(err))
 		os.Exit(1)
 	}
 }
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"slices"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,6 +36,7 @@ func TestLoadConfig(t *testing.T) {
 		APIToken:    "43c48580e5ad47fa820608eca77eb161",
 		WorkspaceID: 123456,
 		Timeout:     5 * time.Second,
+		MaxRetries:  3,
 	}
 
 	if c != expected {
@@ -36,6 +44,40 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfigRetry(t *testing.T) {
+	rc := `{
+		"api_token": "token",
+		"workspace": "123",
+		"retries": 5,
+		"retry_wait": "100ms",
+		"max_retry_wait": "10s",
+		"retry_all": true
+	}`
+	fname := fmt.Sprintf("%s/togglrc", t.TempDir())
+	if err := os.WriteFile(fname, []byte(rc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(rcEnvKey, fname)
+
+	c, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.MaxRetries != 5 || c.RetryWait != 100*time.Millisecond || c.MaxRetryWait != 10*time.Second || !c.RetryAll {
+		t.Errorf("bad retry config: %+v", c)
+	}
+
+	rc = `{"api_token": "token", "workspace": "123", "retry_wait": "soon"}`
+	if err := os.WriteFile(fname, []byte(rc), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
 func Test_findProject(t *testing.T) {
 	projects := []client.Project{
 		{ID: 1, Name: "cartwheel"},
@@ -67,6 +109,625 @@ func Test_findProject(t *testing.T) {
 	}
 }
 
+func Test_errorMessage(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"plain", errors.New("oops"), "oops"},
+		{
+			"rate limited",
+			&client.APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second},
+			"rate limited by toggl - try again in 3s",
+		},
+		{
+			"not found",
+			fmt.Errorf("can't get report: %w", &client.APIError{Method: "GET", URL: "https://go.dev", StatusCode: http.StatusNotFound}),
+			"not found - GET https://go.dev",
+		},
+		{
+			"message",
+			&client.APIError{StatusCode: http.StatusBadRequest, Status: "400 Bad Request", Message: "bad project"},
+			"toggl error (400 Bad Request) - bad project",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := errorMessage(tc.err)
+			if msg != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, msg)
+			}
+		})
+	}
+}
+
+func Test_parseDateRange(t *testing.T) {
+	now := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		since string
+		until string
+		start time.Time
+		end   time.Time
+		err   bool
+	}{
+		{"", "", day(2), day(3), false},
+		{"2023-01-01", "", day(1), day(2), false},
+		{"2023-01-01", "2023-01-05", day(1), day(6), false},
+		{"2023-01-05", "2023-01-01", time.Time{}, time.Time{}, true},
+		{"01-01-2023", "", time.Time{}, time.Time{}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.since+"/"+tc.until, func(t *testing.T) {
+			start, end, err := parseDateRange(newTimeParser(now), tc.since, tc.until)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !start.Equal(tc.start) || !end.Equal(tc.end) {
+				t.Errorf("expected [%v, %v), got [%v, %v)", tc.start, tc.end, start, end)
+			}
+		})
+	}
+}
+
+func Test_entryFlags(t *testing.T) {
+	var ef entryFlags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	ef.register(fs)
+
+	args := []string{"-d", "fix bug", "-t", "dev", "--tag", "bug", "--no-billable"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := ef.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.Description != "fix bug" {
+		t.Errorf("bad description: %q", opts.Description)
+	}
+
+	if !slices.Equal(opts.Tags, []string{"dev", "bug"}) {
+		t.Errorf("bad tags: %v", opts.Tags)
+	}
+
+	if opts.Billable == nil || *opts.Billable {
+		t.Errorf("expected non billable, got %v", opts.Billable)
+	}
+
+	ef.billable = true
+	if _, err := ef.options(); err == nil {
+		t.Error("expected error on both billable and no-billable")
+	}
+}
+
+func Test_entryBillable(t *testing.T) {
+	yes, no := true, false
+	billable := client.Project{Billable: true}
+
+	cases := []struct {
+		name     string
+		billable *bool
+		prj      client.Project
+		expected bool
+	}{
+		{"default billable", nil, billable, true},
+		{"default non billable", nil, client.Project{}, false},
+		{"billable", &yes, client.Project{}, true},
+		{"no billable", &no, billable, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := client.StartOptions{Billable: tc.billable}
+			if got := entryBillable(opts, tc.prj); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_lastStopped(t *testing.T) {
+	stop := time.Now()
+	entries := []client.TimeEntry{
+		{ID: 3, Duration: -1},
+		{ID: 2, Stop: &stop, Duration: 60},
+		{ID: 1, Stop: &stop, Duration: 60},
+	}
+
+	e, ok := lastStopped(entries)
+	if !ok || e.ID != 2 {
+		t.Errorf("expected entry 2, got %+v (ok=%v)", e, ok)
+	}
+
+	if _, ok := lastStopped(entries[:1]); ok {
+		t.Error("found stopped entry in running entries")
+	}
+}
+
+func Test_matchProjectTask(t *testing.T) {
+	prjs := []client.Project{
+		{ID: 1, Name: "CI/CD", Active: true},
+		{ID: 2, Name: "cicd-old", Active: true},
+		{ID: 3, Name: "Web", Active: true},
+		{ID: 4, Name: "Web/Mobile Redesign", Active: true},
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workspaces/1234/projects/3/tasks" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `[{"id": 31, "name": "design", "project_id": 3, "active": true}]`)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(client.Config{APIToken: "token", WorkspaceID: 1234, Timeout: time.Second, BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		prj  int
+		task int
+	}{
+		{"CI/CD", 1, 0},
+		{"ci/cd", 1, 0},
+		{"cicd-o", 2, 0},
+		{"web/mobile redesign", 4, 0},
+		// Fuzzy match of a project with "/" doesn't beat project and task
+		{"web/design", 3, 31},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			prj, task, err := matchProjectTask(context.Background(), c, tc.name, prjs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if prj.ID != tc.prj || task.ID != tc.task {
+				t.Errorf("expected project %d task %d, got %+v (task %+v)", tc.prj, tc.task, prj, task)
+			}
+		})
+	}
+}
+
+func Test_continueOptions(t *testing.T) {
+	e := client.TimeEntry{
+		ProjectID:   7,
+		TaskID:      301,
+		Description: "fix bug",
+		Tags:        []string{"dev"},
+		Billable:    true,
+	}
+
+	opts := continueOptions(e)
+	if opts.Description != "fix bug" || opts.TaskID != 301 || !slices.Equal(opts.Tags, []string{"dev"}) {
+		t.Errorf("bad options: %+v", opts)
+	}
+
+	if opts.Billable == nil || !*opts.Billable {
+		t.Errorf("expected billable, got %v", opts.Billable)
+	}
+}
+
+func Test_matchProject(t *testing.T) {
+	projects := []client.Project{
+		{ID: 1, Name: "api", Active: true},
+		{ID: 2, Name: "apiold", Active: true},
+		{ID: 3, Name: "web", Active: true},
+		{ID: 4, Name: "website", Active: false},
+		{ID: 5, Name: "mobile", Active: false},
+	}
+
+	cases := []struct {
+		query string
+		id    int
+		err   bool
+	}{
+		{"API", 1, false},
+		{"wb", 3, false},
+		{"ap", 0, true},
+		{"banana", 0, true},
+		{"site", 0, true},
+		{"mobile", 0, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			prj, err := matchProject(tc.query, projects)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", prj)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if prj.ID != tc.id {
+				t.Errorf("expected project %d, got %d", tc.id, prj.ID)
+			}
+		})
+	}
+}
+
+func Test_matchTask(t *testing.T) {
+	tasks := []client.Task{
+		{ID: 1, Name: "backend", Active: true},
+		{ID: 2, Name: "frontend", Active: true},
+		{ID: 3, Name: "bugs", Active: false},
+	}
+
+	cases := []struct {
+		query string
+		id    int
+		err   bool
+	}{
+		{"Backend", 1, false},
+		{"fe", 2, false},
+		{"end", 0, true},
+		{"bugs", 0, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			task, err := matchTask(tc.query, tasks)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", task)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if task.ID != tc.id {
+				t.Errorf("expected task %d, got %d", tc.id, task.ID)
+			}
+		})
+	}
+}
+
+func Test_parseEnd(t *testing.T) {
+	start := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		end      string
+		expected time.Time
+		err      bool
+	}{
+		{"10:30", start.Add(90 * time.Minute), false},
+		{"45m", start.Add(45 * time.Minute), false},
+		{"1h30m", start.Add(90 * time.Minute), false},
+		{"08:00", time.Time{}, true},
+		{"-1h", time.Time{}, true},
+		{"soon", time.Time{}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.end, func(t *testing.T) {
+			end, err := parseEnd(newTimeParser(start), tc.end, start)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got %v", end)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !end.Equal(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, end)
+			}
+		})
+	}
+}
+
+func Test_overlapping(t *testing.T) {
+	at := func(h, m int) time.Time {
+		return time.Date(2023, 1, 2, h, m, 0, 0, time.UTC)
+	}
+	stop1, stop2 := at(10, 0), at(12, 0)
+	entries := []client.TimeEntry{
+		{ID: 1, Start: at(9, 0), Stop: &stop1},
+		{ID: 2, Start: at(11, 0), Stop: &stop2},
+	}
+
+	cases := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		ids   []int
+	}{
+		{"before", at(8, 0), at(9, 0), nil},
+		{"between", at(10, 0), at(11, 0), nil},
+		{"inside", at(9, 15), at(9, 45), []int{1}},
+		{"both", at(9, 30), at(11, 30), []int{1, 2}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ids []int
+			for _, e := range overlapping(entries, tc.start, tc.end) {
+				ids = append(ids, e.ID)
+			}
+
+			if !slices.Equal(ids, tc.ids) {
+				t.Errorf("expected %v, got %v", tc.ids, ids)
+			}
+		})
+	}
+}
+
+func Test_reportRange(t *testing.T) {
+	// Wednesday
+	p := newTimeParser(time.Date(2023, 3, 15, 10, 0, 0, 0, time.UTC))
+	day := func(m time.Month, d int) time.Time {
+		return time.Date(2023, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name    string
+		since   string
+		until   string
+		periods []string
+		start   time.Time
+		end     time.Time
+	}{
+		{"default", "", "", nil, day(3, 15), day(3, 15)},
+		{"since", "2023-03-01", "", nil, day(3, 1), day(3, 15)},
+		{"since until", "2023-03-01", "2023-03-05", nil, day(3, 1), day(3, 5)},
+		{"today", "", "", []string{"today"}, day(3, 15), day(3, 15)},
+		{"week", "", "", []string{"week"}, day(3, 13), day(3, 15)},
+		{"last week", "", "", []string{"last-week"}, day(3, 6), day(3, 12)},
+		{"month", "", "", []string{"month"}, day(3, 1), day(3, 15)},
+		{"last month", "", "", []string{"last-month"}, day(2, 1), day(2, 28)},
+		{"ytd", "", "", []string{"ytd"}, day(1, 1), day(3, 15)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, err := reportRange(p, tc.since, tc.until, tc.periods)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !start.Equal(tc.start) || !end.Equal(tc.end) {
+				t.Errorf("expected [%v, %v], got [%v, %v]", tc.start, tc.end, start, end)
+			}
+		})
+	}
+
+	p.weekStart = time.Sunday
+	start, _, err := reportRange(p, "", "", []string{"week"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !start.Equal(day(3, 12)) {
+		t.Errorf("week starting Sunday: expected %v, got %v", day(3, 12), start)
+	}
+
+	errCases := []struct {
+		name    string
+		since   string
+		periods []string
+	}{
+		{"two periods", "", []string{"week", "month"}},
+		{"period and date", "2023-03-01", []string{"week"}},
+		{"future", "tomorrow", nil},
+	}
+
+	for _, tc := range errCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := reportRange(p, tc.since, "", tc.periods); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func Test_reportGroups(t *testing.T) {
+	group, subGroup, err := reportGroups("client", "Project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if group != client.GroupByClient || subGroup != client.GroupByProject {
+		t.Errorf("expected client/project, got %q/%q", group, subGroup)
+	}
+
+	errCases := []struct {
+		name       string
+		groupBy    string
+		subGroupBy string
+	}{
+		{"no group", "", "day"},
+		{"same", "day", "day"},
+		{"unknown", "weather", ""},
+		{"unknown sub group", "day", "weather"},
+	}
+
+	for _, tc := range errCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := reportGroups(tc.groupBy, tc.subGroupBy); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func Test_printGroups(t *testing.T) {
+	groups := []client.ReportGroup{
+		{
+			Title:    "Acme",
+			Duration: 90 * time.Minute,
+			SubGroups: []client.ReportGroup{
+				{Title: "api", Duration: time.Hour},
+				{Title: "web", Duration: 30 * time.Minute},
+			},
+		},
+		{Title: "(no client)", Duration: time.Minute},
+	}
+
+	var buf bytes.Buffer
+	printGroups(&buf, groups, "")
+	expected := "Acme: 01:30:00\n  api: 01:00:00\n  web: 00:30:00\n(no client): 00:01:00\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func Test_sortReports(t *testing.T) {
+	reps := func() []client.Report {
+		return []client.Report{
+			{Project: "web", Client: "Acme", Duration: time.Hour},
+			{Project: "api", Client: "", Duration: 2 * time.Hour},
+			{Project: "CLI", Client: "Acme", Duration: time.Hour},
+		}
+	}
+
+	cases := []struct {
+		by       string
+		expected []string
+	}{
+		{"duration", []string{"api", "CLI", "web"}},
+		{"project", []string{"api", "CLI", "web"}},
+		{"client", []string{"api", "CLI", "web"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.by, func(t *testing.T) {
+			rs := reps()
+			if err := sortReports(rs, tc.by); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, r := range rs {
+				if r.Project != tc.expected[i] {
+					t.Fatalf("expected %v, got %+v", tc.expected, rs)
+				}
+			}
+		})
+	}
+
+	if err := sortReports(reps(), "color"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func Test_printReport(t *testing.T) {
+	reps := []client.Report{
+		{Project: "api", Client: "Acme", Duration: 3 * time.Hour},
+		{Project: "", Duration: 45 * time.Minute},
+	}
+
+	var buf bytes.Buffer
+	if err := printReport(&buf, reps, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `PROJECT       CLIENT   TIME  HOURS       %
+api           Acme    03:00    3.0   80.0%
+(no project)          00:45    0.8   20.0%
+TOTAL                 03:45    3.8  100.0%
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := printReport(&buf, nil, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "TOTAL") || strings.Contains(buf.String(), "NaN") {
+		t.Errorf("bad empty report:\n%s", buf.String())
+	}
+}
+
+func Test_duration2hm(t *testing.T) {
+	d := 26*time.Hour + 3*time.Minute + 59*time.Second
+	if s := duration2hm(d); s != "26:03" {
+		t.Errorf("expected 26:03, got %s", s)
+	}
+
+	if s := duration2str(d); s != "26:03:59" {
+		t.Errorf("expected 26:03:59, got %s", s)
+	}
+}
+
+func Test_stopTime(t *testing.T) {
+	at := func(h, m int) time.Time {
+		return time.Date(2023, 1, 2, h, m, 0, 0, time.UTC)
+	}
+	start, now := at(9, 0), at(18, 0)
+	p := newTimeParser(now)
+
+	cases := []struct {
+		name     string
+		at       string
+		ago      string
+		expected time.Time
+		err      bool
+	}{
+		{"none", "", "", time.Time{}, false},
+		{"at", "17:30", "", at(17, 30), false},
+		{"ago", "", "20m", at(17, 40), false},
+		{"both", "17:30", "20m", time.Time{}, true},
+		{"before start", "08:00", "", time.Time{}, true},
+		{"future", "19:00", "", time.Time{}, true},
+		{"ago too long", "", "10h", time.Time{}, true},
+		{"bad ago", "", "soon", time.Time{}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stop, err := stopTime(p, tc.at, tc.ago, start)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got %v", stop)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !stop.Equal(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, stop)
+			}
+		})
+	}
+}
+
 func TestBadReportDate(t *testing.T) {
 	dir := t.TempDir()
 	exe := fmt.Sprintf("%s/%s", dir, "toggl")
@@ -76,7 +737,15 @@ func TestBadReportDate(t *testing.T) {
 	}
 
 	cmd = exec.Command(exe, "report", "01-02-2023")
+	// Date is parsed after reading the configuration
+	cmd.Env = append(os.Environ(), rcEnvKey+"=togglrc-example")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	if err := cmd.Run(); err == nil {
 		t.Fatal("expected error, got nil")
 	}
+
+	if !strings.Contains(stderr.String(), "bad date") {
+		t.Errorf("expected bad date error, got %q", stderr.String())
+	}
 }
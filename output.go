@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tebeka/toggl/client"
+)
+
+// outputFormat is the command output format
+type outputFormat string
+
+const (
+	formatText  outputFormat = "text"
+	formatJSON  outputFormat = "json"
+	formatJSONL outputFormat = "jsonl"
+	formatCSV   outputFormat = "csv"
+	formatTSV   outputFormat = "tsv"
+)
+
+var outputFormats = []outputFormat{formatText, formatJSON, formatJSONL, formatCSV, formatTSV}
+
+// outFormat and outTemplate are set by the --format and --template flags,
+// either global (before the command) or per command
+var (
+	outFormat   = formatText
+	outTemplate string
+)
+
+// seconds is a duration that is written as whole seconds, and as HH:MM:SS in text
+type seconds time.Duration
+
+func (s seconds) Seconds() int64 {
+	return int64(time.Duration(s).Seconds())
+}
+
+func (s seconds) String() string {
+	return duration2str(time.Duration(s))
+}
+
+func (s seconds) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, s.Seconds(), 10), nil
+}
+
+func (f *outputFormat) String() string {
+	return string(*f)
+}
+
+func (f *outputFormat) Set(value string) error {
+	for _, of := range outputFormats {
+		if strings.EqualFold(value, string(of)) {
+			*f = of
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown format %q (should be one of %s)", value, formatNames())
+}
+
+func formatNames() string {
+	names := make([]string, len(outputFormats))
+	for i, f := range outputFormats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// registerOutput adds the --format and --template flags to fs
+func registerOutput(fs *flag.FlagSet) {
+	fs.Var(&outFormat, "format", "output format: "+formatNames())
+	// Not StringVar, it'll reset a global --template to the default
+	fs.Func("template", "Go template for each record, or name of a template in the configuration", func(value string) error {
+		outTemplate = value
+		return nil
+	})
+}
+
+// entryRecord is the machine readable output of a time entry
+type entryRecord struct {
+	ID          int        `json:"id"`
+	ProjectID   int        `json:"project_id"`
+	Project     string     `json:"project"`
+	Client      string     `json:"client"`
+	TaskID      int        `json:"task_id"`
+	Task        string     `json:"task"`
+	Description string     `json:"description"`
+	Tags        []string   `json:"tags"`
+	Billable    bool       `json:"billable"`
+	Start       time.Time  `json:"start"`
+	Stop        *time.Time `json:"stop"`
+	Duration    seconds    `json:"duration"`
+}
+
+// newEntryRecord returns the record of e, tasks is task ID -> name (see taskNames)
+func newEntryRecord(e client.TimeEntry, prjs map[int]client.Project, tasks map[int]string, loc *time.Location) entryRecord {
+	prj := prjs[e.ProjectID]
+	r := entryRecord{
+		ID:          e.ID,
+		ProjectID:   e.ProjectID,
+		Project:     prj.Name,
+		Client:      prj.ClientName,
+		TaskID:      e.TaskID,
+		Task:        tasks[e.TaskID],
+		Description: e.Description,
+		Tags:        e.Tags,
+		Billable:    e.Billable,
+		Start:       e.Start.In(loc),
+		Duration:    seconds(e.Elapsed()),
+	}
+
+	if r.Tags == nil {
+		r.Tags = []string{}
+	}
+
+	if e.Stop != nil {
+		stop := e.Stop.In(loc)
+		r.Stop = &stop
+	}
+
+	return r
+}
+
+// Elapsed is the entry duration, "{{.Elapsed}}" reads better in templates
+func (r entryRecord) Elapsed() seconds {
+	return r.Duration
+}
+
+// projectRecord is the machine readable output of a project
+type projectRecord struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	ClientID       int    `json:"client_id"`
+	Client         string `json:"client"`
+	WorkspaceID    int    `json:"workspace_id"`
+	Active         bool   `json:"active"`
+	Billable       bool   `json:"billable"`
+	Color          string `json:"color"`
+	ActualHours    int    `json:"actual_hours"`
+	EstimatedHours int    `json:"estimated_hours"`
+}
+
+func newProjectRecord(prj client.Project) projectRecord {
+	return projectRecord{
+		ID:             prj.ID,
+		Name:           prj.Name,
+		ClientID:       prj.ClientID,
+		Client:         prj.ClientName,
+		WorkspaceID:    prj.WorkspaceID,
+		Active:         prj.Active,
+		Billable:       prj.Billable,
+		Color:          prj.Color,
+		ActualHours:    prj.ActualHours,
+		EstimatedHours: prj.EstimatedHours,
+	}
+}
+
+// clientRecord is the machine readable output of a client
+type clientRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// tagRecord is the machine readable output of a tag
+type tagRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// reportRecord is the machine readable output of a report line
+type reportRecord struct {
+	Project  string  `json:"project"`
+	Client   string  `json:"client"`
+	Duration seconds `json:"duration"`
+}
+
+// groupRecord is the machine readable output of a grouped report line
+type groupRecord struct {
+	Group    string  `json:"group"`
+	SubGroup string  `json:"subgroup"`
+	Duration seconds `json:"duration"`
+}
+
+// groupRecords flattens groups to records, groups with sub groups are emitted as their sub groups
+func groupRecords(groups []client.ReportGroup) []groupRecord {
+	var records []groupRecord
+	for _, g := range groups {
+		if len(g.SubGroups) == 0 {
+			records = append(records, groupRecord{g.Title, "", seconds(g.Duration)})
+			continue
+		}
+
+		for _, sg := range g.SubGroups {
+			records = append(records, groupRecord{g.Title, sg.Title, seconds(sg.Duration)})
+		}
+	}
+	return records
+}
+
+// writeRecords writes records to w in outFormat (or with outTemplate), text output is done by text
+func writeRecords[T any](w io.Writer, records []T, text func() error) error {
+	if records == nil {
+		records = []T{}
+	}
+
+	if outTemplate != "" {
+		if outFormat != formatText {
+			return fmt.Errorf("can't use both --format and --template")
+		}
+
+		tmpl, err := loadTemplate(outTemplate)
+		if err != nil {
+			return err
+		}
+		return executeTemplate(w, tmpl, records)
+	}
+
+	switch outFormat {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case formatJSONL:
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatCSV, formatTSV:
+		cw := csv.NewWriter(w)
+		if outFormat == formatTSV {
+			cw.Comma = '\t'
+		}
+
+		var zero T
+		if err := cw.Write(recordHeader(reflect.TypeOf(zero))); err != nil {
+			return err
+		}
+
+		for _, r := range records {
+			if err := cw.Write(recordValues(reflect.ValueOf(r))); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return text()
+}
+
+// recordHeader returns the JSON field names of a record struct
+func recordHeader(typ reflect.Type) []string {
+	header := make([]string, typ.NumField())
+	for i := range header {
+		f := typ.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		header[i] = name
+	}
+	return header
+}
+
+// recordValues returns the field values of a record struct as CSV values
+func recordValues(v reflect.Value) []string {
+	values := make([]string, v.NumField())
+	for i := range values {
+		switch f := v.Field(i).Interface().(type) {
+		case seconds:
+			values[i] = strconv.FormatInt(f.Seconds(), 10)
+		case time.Time:
+			values[i] = timeValue(&f)
+		case *time.Time:
+			values[i] = timeValue(f)
+		case []string:
+			values[i] = strings.Join(f, ",")
+		default:
+			values[i] = fmt.Sprint(f)
+		}
+	}
+	return values
+}
+
+func timeValue(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// templateFuncs are the helper functions available in --template
+var templateFuncs = template.FuncMap{
+	"hms": func(d seconds) string {
+		return duration2str(time.Duration(d))
+	},
+	"hm": func(d seconds) string {
+		return duration2hm(time.Duration(d))
+	},
+	"hours": func(d seconds) string {
+		return fmt.Sprintf("%.2f", time.Duration(d).Hours())
+	},
+	"minutes": func(d seconds) int64 {
+		return int64(time.Duration(d).Minutes())
+	},
+	"join": func(sep string, values []string) string {
+		return strings.Join(values, sep)
+	},
+}
+
+// loadTemplate parses the template named text in the configuration "templates", or text itself
+func loadTemplate(text string) (*template.Template, error) {
+	rc, err := readRC()
+	if err != nil {
+		return nil, err
+	}
+
+	name := "template"
+	if named, ok := rc.Templates[text]; ok {
+		name, text = text, named
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("bad template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// executeTemplate writes each record with tmpl, one per line
+func executeTemplate[T any](w io.Writer, tmpl *template.Template, records []T) error {
+	var buf bytes.Buffer
+	for _, r := range records {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, r); err != nil {
+			return err
+		}
+
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/tebeka/toggl/client"
+)
+
+func Test_outputFormatSet(t *testing.T) {
+	var f outputFormat
+	if err := f.Set("JSONL"); err != nil {
+		t.Fatal(err)
+	}
+
+	if f != formatJSONL {
+		t.Errorf("expected %q, got %q", formatJSONL, f)
+	}
+
+	if err := f.Set("xml"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func setFormat(t *testing.T, f outputFormat) {
+	old := outFormat
+	outFormat = f
+	t.Cleanup(func() { outFormat = old })
+}
+
+func testRecords() []entryRecord {
+	start := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+	stop := start.Add(90 * time.Minute)
+	entries := []client.TimeEntry{
+		{ID: 1, ProjectID: 7, TaskID: 301, Description: "a, b", Tags: []string{"x", "y"}, Start: start, Stop: &stop},
+		{ID: 2, Start: stop, Duration: 60},
+	}
+
+	prjs := map[int]client.Project{7: {Name: "api", ID: 7, ClientName: "Acme"}}
+	tasks := map[int]string{301: "backend"}
+	records := make([]entryRecord, len(entries))
+	for i, e := range entries {
+		records[i] = newEntryRecord(e, prjs, tasks, time.UTC)
+	}
+	return records
+}
+
+func Test_writeRecords(t *testing.T) {
+	textCalled := false
+	text := func() error {
+		textCalled = true
+		return nil
+	}
+
+	cases := []struct {
+		format   outputFormat
+		expected string
+	}{
+		{
+			formatCSV,
+			"id,project_id,project,client,task_id,task,description,tags,billable,start,stop,duration\n" +
+				"1,7,api,Acme,301,backend,\"a, b\",\"x,y\",false,2023-01-02T09:00:00Z,2023-01-02T10:30:00Z,5400\n" +
+				"2,0,,,0,,,,false,2023-01-02T10:30:00Z,,60\n",
+		},
+		{
+			formatTSV,
+			"id\tproject_id\tproject\tclient\ttask_id\ttask\tdescription\ttags\tbillable\tstart\tstop\tduration\n" +
+				"1\t7\tapi\tAcme\t301\tbackend\ta, b\tx,y\tfalse\t2023-01-02T09:00:00Z\t2023-01-02T10:30:00Z\t5400\n" +
+				"2\t0\t\t\t0\t\t\t\tfalse\t2023-01-02T10:30:00Z\t\t60\n",
+		},
+		{
+			formatJSONL,
+			`{"id":1,"project_id":7,"project":"api","client":"Acme","task_id":301,"task":"backend","description":"a, b","tags":["x","y"],"billable":false,"start":"2023-01-02T09:00:00Z","stop":"2023-01-02T10:30:00Z","duration":5400}` + "\n" +
+				`{"id":2,"project_id":0,"project":"","client":"","task_id":0,"task":"","description":"","tags":[],"billable":false,"start":"2023-01-02T10:30:00Z","stop":null,"duration":60}` + "\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			setFormat(t, tc.format)
+			var buf bytes.Buffer
+			if err := writeRecords(&buf, testRecords(), text); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, buf.String())
+			}
+		})
+	}
+
+	if textCalled {
+		t.Error("text output called")
+	}
+
+	setFormat(t, formatText)
+	if err := writeRecords(&bytes.Buffer{}, testRecords(), text); err != nil {
+		t.Fatal(err)
+	}
+
+	if !textCalled {
+		t.Error("text output not called")
+	}
+}
+
+func Test_writeRecordsJSON(t *testing.T) {
+	setFormat(t, formatJSON)
+
+	var buf bytes.Buffer
+	var records []groupRecord
+	if err := writeRecords(&buf, records, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var out []groupRecord
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out == nil || len(out) != 0 {
+		t.Errorf("expected empty list, got %q", buf.String())
+	}
+}
+
+func Test_groupRecords(t *testing.T) {
+	groups := []client.ReportGroup{
+		{
+			Title:    "Acme",
+			Duration: 90 * time.Minute,
+			SubGroups: []client.ReportGroup{
+				{Title: "api", Duration: time.Hour},
+				{Title: "web", Duration: 30 * time.Minute},
+			},
+		},
+		{Title: "(no client)", Duration: time.Minute},
+	}
+
+	expected := []groupRecord{
+		{"Acme", "api", seconds(time.Hour)},
+		{"Acme", "web", seconds(30 * time.Minute)},
+		{"(no client)", "", seconds(time.Minute)},
+	}
+
+	records := groupRecords(groups)
+	if len(records) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, records)
+	}
+
+	for i := range expected {
+		if records[i] != expected[i] {
+			t.Errorf("%d: expected %v, got %v", i, expected[i], records[i])
+		}
+	}
+}
+
+func Test_writeRecordsTemplate(t *testing.T) {
+	rc := `{"templates": {"tmux": "{{.Client}}/{{.Project}} {{hm .Elapsed}}"}}`
+	fname := fmt.Sprintf("%s/togglrc", t.TempDir())
+	if err := os.WriteFile(fname, []byte(rc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(rcEnvKey, fname)
+
+	cases := []struct {
+		template string
+		expected string
+	}{
+		{"tmux", "Acme/api 01:30\n/ 00:01\n"},
+		{"{{.ID}} {{.Duration}} {{hours .Duration}} {{join \";\" .Tags}}\n", "1 01:30:00 1.50 x;y\n2 00:01:00 0.02 \n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.template, func(t *testing.T) {
+			old := outTemplate
+			outTemplate = tc.template
+			t.Cleanup(func() { outTemplate = old })
+
+			var buf bytes.Buffer
+			if err := writeRecords(&buf, testRecords(), nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, buf.String())
+			}
+		})
+	}
+
+	outTemplate = "{{.NoSuchField}}"
+	t.Cleanup(func() { outTemplate = "" })
+	if err := writeRecords(&bytes.Buffer{}, testRecords(), nil); err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	setFormat(t, formatJSON)
+	outTemplate = "tmux"
+	if err := writeRecords(&bytes.Buffer{}, testRecords(), nil); err == nil {
+		t.Error("expected error on --format with --template, got nil")
+	}
+}
+
+func Test_registerOutputGlobal(t *testing.T) {
+	setFormat(t, formatText)
+	t.Cleanup(func() { outTemplate = "" })
+
+	globals := flag.NewFlagSet("toggl", flag.ContinueOnError)
+	registerOutput(globals)
+	if err := globals.Parse([]string{"-format", "csv", "-template", "{{.ID}}", "status"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Command flags should not reset global ones
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	registerOutput(fs)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if outFormat != formatCSV || outTemplate != "{{.ID}}" {
+		t.Errorf("global flags reset: format=%q, template=%q", outFormat, outTemplate)
+	}
+}
@@ -0,0 +1,518 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+
+	"github.com/tebeka/toggl/client"
+)
+
+var colorRe = regexp.MustCompile(`^#?([0-9a-fA-F]{6})$`)
+
+// parseColor parses hex color (e.g. "#06aaf5" or "06aaf5") to toggl's "#06aaf5"
+func parseColor(s string) (string, error) {
+	m := colorRe.FindStringSubmatch(s)
+	if m == nil {
+		return "", fmt.Errorf("bad color %q (should be hex, e.g. #06aaf5)", s)
+	}
+
+	return "#" + strings.ToLower(m[1]), nil
+}
+
+// runSub runs the sub command named in args[0], or list if there's no sub command
+func runSub(ctx context.Context, name string, subs []cmd, list func(context.Context, []string) error, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return list(ctx, args)
+	}
+
+	names := make([]string, len(subs))
+	for i, sub := range subs {
+		if sub.name == args[0] {
+			return sub.fn(ctx, args[1:])
+		}
+		names[i] = sub.name
+	}
+
+	return fmt.Errorf("unknown %s command %q (should be one of %s)", name, args[0], strings.Join(names, ", "))
+}
+
+// subUsage returns the usage line of command with subs
+func subUsage(name string, subs []cmd) string {
+	names := make([]string, len(subs))
+	for i, sub := range subs {
+		names[i] = sub.name
+	}
+
+	return fmt.Sprintf("%s [%s] [flags] [arguments]", name, strings.Join(names, "|"))
+}
+
+// Sub commands, set in init since the list commands help refers to them
+var projectsCmds, clientsCmds []cmd
+
+func init() {
+	projectsCmds = []cmd{
+		{"archive", "archive project", projectsArchiveCmd},
+		{"color", "set project color", projectsColorCmd},
+		{"create", "create project", projectsCreateCmd},
+		{"list", "list projects", projectsListCmd},
+		{"rename", "rename project", projectsRenameCmd},
+		{"unarchive", "unarchive project", projectsUnarchiveCmd},
+	}
+
+	clientsCmds = []cmd{
+		{"create", "create client", clientsCreateCmd},
+		{"delete", "delete client", clientsDeleteCmd},
+		{"list", "list clients", clientsListCmd},
+		{"rename", "rename client", clientsRenameCmd},
+	}
+}
+
+func projectsCmd(ctx context.Context, args []string) error {
+	return runSub(ctx, "projects", projectsCmds, projectsListCmd, args)
+}
+
+func projectsListCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("projects", flag.ExitOnError)
+	all := fs.Bool("all", false, "include archived projects")
+	long := fs.Bool("long", false, "show project details")
+	registerOutput(fs)
+	simpleHelp(fs, subUsage("projects", projectsCmds), "List or manage projects (default list).")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !*all {
+		prjs = activeProjects(prjs, true)
+	}
+
+	sort.Slice(prjs, func(i, j int) bool {
+		return strings.ToLower(prjs[i].FullName()) < strings.ToLower(prjs[j].FullName())
+	})
+
+	records := make([]projectRecord, len(prjs))
+	for i, prj := range prjs {
+		records[i] = newProjectRecord(prj)
+	}
+
+	return writeRecords(os.Stdout, records, func() error {
+		if *long {
+			return printProjects(os.Stdout, prjs)
+		}
+
+		for _, prj := range prjs {
+			if prj.Active {
+				fmt.Println(prj.FullName())
+			} else {
+				fmt.Printf("%s (archived)\n", prj.FullName())
+			}
+		}
+		return nil
+	})
+}
+
+// printProjects prints projects details as a table
+func printProjects(w io.Writer, prjs []client.Project) error {
+	rows := [][]string{{"PROJECT", "CLIENT", "STATUS", "BILLABLE", "COLOR", "HOURS", "ESTIMATE"}}
+	for _, prj := range prjs {
+		status := "active"
+		if !prj.Active {
+			status = "archived"
+		}
+
+		billable := "no"
+		if prj.Billable {
+			billable = "yes"
+		}
+
+		estimate := "-"
+		if prj.EstimatedHours > 0 {
+			estimate = strconv.Itoa(prj.EstimatedHours)
+		}
+
+		row := []string{prj.Name, prj.ClientName, status, billable, prj.Color, strconv.Itoa(prj.ActualHours), estimate}
+		rows = append(rows, row)
+	}
+
+	return printTable(w, rows, 5)
+}
+
+func projectsCreateCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("projects create", flag.ExitOnError)
+	clientName := fs.String("client", "", "client name")
+	color := fs.String("color", "", "hex color (e.g. #06aaf5)")
+	simpleHelp(fs, "projects create [flags] <name>", "Create project.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	var opts client.ProjectOptions
+	if *color != "" {
+		var err error
+		if opts.Color, err = parseColor(*color); err != nil {
+			return err
+		}
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	if *clientName != "" {
+		cs, err := c.Customers(ctx)
+		if err != nil {
+			return err
+		}
+
+		cust, err := matchClient(*clientName, cs)
+		if err != nil {
+			return err
+		}
+		opts.ClientID = cust.ID
+	}
+
+	prj, err := c.CreateProject(ctx, fs.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created project %s (%d)\n", prj.Name, prj.ID)
+	return nil
+}
+
+// updateProject applies u to the active (or archived if active is false) project matching name,
+// and returns the project before the update
+func updateProject(ctx context.Context, name string, active bool, u client.ProjectUpdate) (client.Project, error) {
+	c, err := newClient()
+	if err != nil {
+		return client.Project{}, err
+	}
+
+	prjs, err := c.Projects(ctx)
+	if err != nil {
+		return client.Project{}, err
+	}
+
+	var prj client.Project
+	if active {
+		prj, err = matchProject(name, prjs)
+	} else {
+		prj, err = matchProjectIn(name, activeProjects(prjs, false))
+	}
+	if err != nil {
+		return client.Project{}, err
+	}
+
+	if _, err := c.UpdateProject(ctx, prj.ID, u); err != nil {
+		return client.Project{}, err
+	}
+
+	return prj, nil
+}
+
+func projectsRenameCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("projects rename", flag.ExitOnError)
+	simpleHelp(fs, "projects rename <project> <new name>", "Rename project.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	name := fs.Arg(1)
+	if name == "" {
+		return fmt.Errorf("empty project name")
+	}
+
+	prj, err := updateProject(ctx, fs.Arg(0), true, client.ProjectUpdate{Name: &name})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Renamed %s to %s\n", prj.Name, name)
+	return nil
+}
+
+// setActive archives or unarchives a project
+func setActive(ctx context.Context, args []string, active bool) error {
+	cmd, verb, desc := "archive", "Archived", "Archive project."
+	if active {
+		cmd, verb, desc = "unarchive", "Unarchived", "Unarchive project."
+	}
+
+	fs := flag.NewFlagSet("projects "+cmd, flag.ExitOnError)
+	simpleHelp(fs, fmt.Sprintf("projects %s <project>", cmd), desc)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	prj, err := updateProject(ctx, fs.Arg(0), !active, client.ProjectUpdate{Active: &active})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s\n", verb, prj.Name)
+	return nil
+}
+
+func projectsArchiveCmd(ctx context.Context, args []string) error {
+	return setActive(ctx, args, false)
+}
+
+func projectsUnarchiveCmd(ctx context.Context, args []string) error {
+	return setActive(ctx, args, true)
+}
+
+func projectsColorCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("projects color", flag.ExitOnError)
+	simpleHelp(fs, "projects color <project> <color>", "Set project color (hex, e.g. #06aaf5).")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	color, err := parseColor(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	prj, err := updateProject(ctx, fs.Arg(0), true, client.ProjectUpdate{Color: &color})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s color is %s\n", prj.Name, color)
+	return nil
+}
+
+// noMatchError is returned by matchName when nothing matches
+type noMatchError struct {
+	kind string
+	name string
+}
+
+func (e *noMatchError) Error() string {
+	return fmt.Sprintf("no %s match %s", e.kind, e.name)
+}
+
+// matchName returns the single item whose name matches name, an exact match wins
+func matchName[T any](kind, name string, items []T, nameOf func(T) string) (T, error) {
+	var matches []T
+	for _, item := range items {
+		if strings.EqualFold(nameOf(item), name) {
+			return item, nil
+		}
+
+		if fuzzy.MatchFold(name, nameOf(item)) {
+			matches = append(matches, item)
+		}
+	}
+
+	var zero T
+	switch len(matches) {
+	case 0:
+		return zero, &noMatchError{kind, name}
+	case 1:
+		return matches[0], nil
+	}
+
+	names := make([]string, len(matches))
+	for i, item := range matches {
+		names[i] = nameOf(item)
+	}
+	return zero, fmt.Errorf("too many matches to %q: %s", name, projectsStr(names))
+}
+
+// matchExact returns the item whose name is name (ignoring case) or whose ID is name.
+// Destructive commands use it instead of matchName so a typo won't hit the wrong item.
+func matchExact[T any](kind, name string, items []T, nameOf func(T) string, idOf func(T) int) (T, error) {
+	id, err := strconv.Atoi(name)
+	for _, item := range items {
+		if strings.EqualFold(nameOf(item), name) || (err == nil && idOf(item) == id) {
+			return item, nil
+		}
+	}
+
+	var zero T
+	return zero, fmt.Errorf("no %s named %s (use the exact name or ID)", kind, name)
+}
+
+// matchClient returns the single client matching name
+func matchClient(name string, cs []client.Customer) (client.Customer, error) {
+	return matchName("client", name, cs, func(c client.Customer) string { return c.Name })
+}
+
+func clientsCmd(ctx context.Context, args []string) error {
+	return runSub(ctx, "clients", clientsCmds, clientsListCmd, args)
+}
+
+func clientsListCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("clients", flag.ExitOnError)
+	registerOutput(fs)
+	simpleHelp(fs, subUsage("clients", clientsCmds), "List or manage clients (default list).")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	cs, err := c.Customers(ctx)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(cs, func(i, j int) bool {
+		return strings.ToLower(cs[i].Name) < strings.ToLower(cs[j].Name)
+	})
+
+	records := make([]clientRecord, len(cs))
+	for i, cust := range cs {
+		records[i] = clientRecord{cust.ID, cust.Name}
+	}
+
+	return writeRecords(os.Stdout, records, func() error {
+		for _, cust := range cs {
+			fmt.Println(cust.Name)
+		}
+		return nil
+	})
+}
+
+func clientsCreateCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("clients create", flag.ExitOnError)
+	simpleHelp(fs, "clients create <name>", "Create client.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	cust, err := c.CreateClient(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created client %s (%d)\n", cust.Name, cust.ID)
+	return nil
+}
+
+// findClient returns the API client and the client matching name, exact uses matchExact
+func findClient(ctx context.Context, name string, exact bool) (*client.Client, client.Customer, error) {
+	c, err := newClient()
+	if err != nil {
+		return nil, client.Customer{}, err
+	}
+
+	cs, err := c.Customers(ctx)
+	if err != nil {
+		return nil, client.Customer{}, err
+	}
+
+	var cust client.Customer
+	if exact {
+		cust, err = matchExact("client", name, cs, func(c client.Customer) string { return c.Name }, func(c client.Customer) int { return c.ID })
+	} else {
+		cust, err = matchClient(name, cs)
+	}
+	if err != nil {
+		return nil, client.Customer{}, err
+	}
+
+	return c, cust, nil
+}
+
+func clientsRenameCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("clients rename", flag.ExitOnError)
+	simpleHelp(fs, "clients rename <client> <new name>", "Rename client.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, cust, err := findClient(ctx, fs.Arg(0), false)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.RenameClient(ctx, cust.ID, fs.Arg(1)); err != nil {
+		return err
+	}
+
+	fmt.Printf("Renamed %s to %s\n", cust.Name, fs.Arg(1))
+	return nil
+}
+
+func clientsDeleteCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("clients delete", flag.ExitOnError)
+	simpleHelp(fs, "clients delete <client>", "Delete client, client is the exact name or ID.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, cust, err := findClient(ctx, fs.Arg(0), true)
+	if err != nil {
+		return err
+	}
+
+	if err := c.DeleteClient(ctx, cust.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted client %s\n", cust.Name)
+	return nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/tebeka/toggl/client"
+)
+
+func Test_parseColor(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected string
+	}{
+		{"#06aaf5", "#06aaf5"},
+		{"06AAF5", "#06aaf5"},
+	}
+
+	for _, tc := range cases {
+		out, err := parseColor(tc.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if out != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.in, tc.expected, out)
+		}
+	}
+
+	for _, in := range []string{"", "red", "#06aaf", "#06aaf5f"} {
+		if _, err := parseColor(in); err == nil {
+			t.Errorf("%q: expected error, got nil", in)
+		}
+	}
+}
+
+func Test_matchClient(t *testing.T) {
+	cs := []client.Customer{
+		{ID: 1, Name: "Acme"},
+		{ID: 2, Name: "Acme Labs"},
+		{ID: 3, Name: "Globex"},
+	}
+
+	cases := []struct {
+		name     string
+		expected int
+	}{
+		{"acme", 1},
+		{"glob", 3},
+		{"labs", 2},
+	}
+
+	for _, tc := range cases {
+		c, err := matchClient(tc.name, cs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.ID != tc.expected {
+			t.Errorf("%q: expected %d, got %d", tc.name, tc.expected, c.ID)
+		}
+	}
+
+	for _, name := range []string{"ac", "initech"} {
+		if _, err := matchClient(name, cs); err == nil {
+			t.Errorf("%q: expected error, got nil", name)
+		}
+	}
+}
+
+func Test_matchExact(t *testing.T) {
+	cs := []client.Customer{
+		{ID: 1, Name: "Acme"},
+		{ID: 2, Name: "Globex"},
+	}
+	nameOf := func(c client.Customer) string { return c.Name }
+	idOf := func(c client.Customer) int { return c.ID }
+
+	for name, expected := range map[string]int{"acme": 1, "2": 2} {
+		c, err := matchExact("client", name, cs, nameOf, idOf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.ID != expected {
+			t.Errorf("%q: expected %d, got %d", name, expected, c.ID)
+		}
+	}
+
+	for _, name := range []string{"ac", "glob", "3"} {
+		if _, err := matchExact("client", name, cs, nameOf, idOf); err == nil {
+			t.Errorf("%q: expected error, got nil", name)
+		}
+	}
+}
+
+func Test_runSub(t *testing.T) {
+	var called string
+	fn := func(name string) func(context.Context, []string) error {
+		return func(_ context.Context, args []string) error {
+			called = name
+			if len(args) > 0 {
+				called += " " + args[0]
+			}
+			return nil
+		}
+	}
+
+	subs := []cmd{
+		{"create", "create", fn("create")},
+		{"list", "list", fn("list")},
+	}
+
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "list"},
+		{[]string{"-format", "json"}, "list -format"},
+		{[]string{"create", "x"}, "create x"},
+	}
+
+	for _, tc := range cases {
+		called = ""
+		if err := runSub(context.Background(), "things", subs, fn("list"), tc.args); err != nil {
+			t.Fatal(err)
+		}
+
+		if called != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, called)
+		}
+	}
+
+	if err := runSub(context.Background(), "things", subs, fn("list"), []string{"frob"}); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func Test_printProjects(t *testing.T) {
+	prjs := []client.Project{
+		{Name: "api", ClientName: "Acme", Active: true, Billable: true, Color: "#06aaf5", ActualHours: 120, EstimatedHours: 200},
+		{Name: "old", Active: false, ActualHours: 3},
+	}
+
+	var buf bytes.Buffer
+	if err := printProjects(&buf, prjs); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `PROJECT  CLIENT  STATUS    BILLABLE  COLOR    HOURS  ESTIMATE
+api      Acme    active    yes       #06aaf5    120       200
+old              archived  no                     3         -
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func Test_activeProjects(t *testing.T) {
+	prjs := []client.Project{
+		{ID: 1, Active: true},
+		{ID: 2, Active: false},
+		{ID: 3, Active: true},
+	}
+
+	if active := activeProjects(prjs, true); len(active) != 2 || active[0].ID != 1 || active[1].ID != 3 {
+		t.Errorf("bad active projects: %+v", active)
+	}
+
+	if archived := activeProjects(prjs, false); len(archived) != 1 || archived[0].ID != 2 {
+		t.Errorf("bad archived projects: %+v", archived)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/tebeka/toggl/client"
+)
+
+// Sub commands, set in init (see projectsCmds)
+var tagsCmds []cmd
+
+func init() {
+	tagsCmds = []cmd{
+		{"create", "create tag", tagsCreateCmd},
+		{"delete", "delete tag", tagsDeleteCmd},
+		{"list", "list tags", tagsListCmd},
+		{"rename", "rename tag", tagsRenameCmd},
+	}
+}
+
+// matchTag returns the single tag matching name
+func matchTag(name string, tags []client.Tag) (client.Tag, error) {
+	return matchName("tag", name, tags, func(t client.Tag) string { return t.Name })
+}
+
+// resolveTagNames maps names to existing tag names, unknown names are returned as is (toggl creates them).
+// It returns the tag names and warnings on unknown tags and on fuzzy matches, so a tag is never replaced silently.
+func resolveTagNames(names []string, tags []client.Tag) ([]string, []string, error) {
+	var out, warnings []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		tag, err := matchTag(name, tags)
+		var nme *noMatchError
+		switch {
+		case err == nil:
+			if !strings.EqualFold(tag.Name, name) {
+				warnings = append(warnings, fmt.Sprintf("using tag %q for %q", tag.Name, name))
+			}
+			name = tag.Name
+		case errors.As(err, &nme):
+			warnings = append(warnings, fmt.Sprintf("unknown tag %q, creating it", name))
+		default:
+			return nil, nil, err
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+
+	return out, warnings, nil
+}
+
+// resolveTags resolves names to existing tags, printing warnings on unknown and fuzzy matched tags
+func resolveTags(ctx context.Context, c *client.Client, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return names, nil
+	}
+
+	tags, err := c.Tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out, warnings, err := resolveTagNames(names, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+
+	return out, nil
+}
+
+func tagsCmd(ctx context.Context, args []string) error {
+	return runSub(ctx, "tags", tagsCmds, tagsListCmd, args)
+}
+
+func tagsListCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tags", flag.ExitOnError)
+	registerOutput(fs)
+	simpleHelp(fs, subUsage("tags", tagsCmds), "List or manage tags (default list).")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	tags, err := c.Tags(ctx)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i].Name) < strings.ToLower(tags[j].Name)
+	})
+
+	records := make([]tagRecord, len(tags))
+	for i, tag := range tags {
+		records[i] = tagRecord{tag.ID, tag.Name}
+	}
+
+	return writeRecords(os.Stdout, records, func() error {
+		for _, tag := range tags {
+			fmt.Println(tag.Name)
+		}
+		return nil
+	})
+}
+
+func tagsCreateCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tags create", flag.ExitOnError)
+	simpleHelp(fs, "tags create <name>", "Create tag.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	tag, err := c.CreateTag(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created tag %s (%d)\n", tag.Name, tag.ID)
+	return nil
+}
+
+// findTag returns the API client and the tag matching name, exact uses matchExact
+func findTag(ctx context.Context, name string, exact bool) (*client.Client, client.Tag, error) {
+	c, err := newClient()
+	if err != nil {
+		return nil, client.Tag{}, err
+	}
+
+	tags, err := c.Tags(ctx)
+	if err != nil {
+		return nil, client.Tag{}, err
+	}
+
+	var tag client.Tag
+	if exact {
+		tag, err = matchExact("tag", name, tags, func(t client.Tag) string { return t.Name }, func(t client.Tag) int { return t.ID })
+	} else {
+		tag, err = matchTag(name, tags)
+	}
+	if err != nil {
+		return nil, client.Tag{}, err
+	}
+
+	return c, tag, nil
+}
+
+func tagsRenameCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tags rename", flag.ExitOnError)
+	simpleHelp(fs, "tags rename <tag> <new name>", "Rename tag.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, tag, err := findTag(ctx, fs.Arg(0), false)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.RenameTag(ctx, tag.ID, fs.Arg(1)); err != nil {
+		return err
+	}
+
+	fmt.Printf("Renamed %s to %s\n", tag.Name, fs.Arg(1))
+	return nil
+}
+
+func tagsDeleteCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tags delete", flag.ExitOnError)
+	simpleHelp(fs, "tags delete <tag>", "Delete tag, tag is the exact name or ID.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("wrong number of arguments")
+	}
+
+	c, tag, err := findTag(ctx, fs.Arg(0), true)
+	if err != nil {
+		return err
+	}
+
+	if err := c.DeleteTag(ctx, tag.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted tag %s\n", tag.Name)
+	return nil
+}
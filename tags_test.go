@@ -0,0 +1,36 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tebeka/toggl/client"
+)
+
+func Test_resolveTagNames(t *testing.T) {
+	tags := []client.Tag{
+		{ID: 1, Name: "dev"},
+		{ID: 2, Name: "meeting"},
+		{ID: 3, Name: "devops"},
+	}
+
+	names, warnings, err := resolveTagNames([]string{"DEV", "meet", "urgent", "dev"}, tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"dev", "meeting", "urgent"}; !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	// Case only difference is not a warning
+	expected := []string{`using tag "meeting" for "meet"`, `unknown tag "urgent", creating it`}
+	if !slices.Equal(warnings, expected) {
+		t.Errorf("expected warnings %v, got %v", expected, warnings)
+	}
+
+	// "de" matches both dev and devops
+	if _, _, err := resolveTagNames([]string{"de"}, tags); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
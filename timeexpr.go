@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeParser parses time expressions such as "15m ago", "yesterday 14:00",
+// "monday 9am" or "2023-01-02 15:04" relative to now (and in its location).
+type timeParser struct {
+	now       time.Time
+	weekStart time.Weekday
+}
+
+var (
+	// Full timestamps, RFC3339 is handled separately since it has a zone
+	timestampLayouts = []string{
+		"2006-01-02 15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02t15:04",
+		"2006-01-02t15:04:05",
+	}
+
+	clockRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(?::(\d{2}))? ?(am|pm)?$`)
+	spanRe  = regexp.MustCompile(`(\d+(?:\.\d+)?) ?([a-z]+)`)
+
+	spanUnits = map[string]time.Duration{
+		"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+		"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+		"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	}
+
+	// Days are not fixed durations (DST), we count them separately
+	spanDays = map[string]int{
+		"d": 1, "day": 1, "days": 1,
+		"w": 7, "wk": 7, "week": 7, "weeks": 7,
+	}
+
+	weekdays = map[string]time.Weekday{
+		"sunday": time.Sunday, "sun": time.Sunday,
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+	}
+)
+
+func newTimeParser(now time.Time) timeParser {
+	return timeParser{now: now, weekStart: time.Monday}
+}
+
+// parse parses a time expression, times of day are on today
+func (p timeParser) parse(s string) (time.Time, error) {
+	return p.parseOn(s, p.now)
+}
+
+// parseOn parses a time expression, times of day (e.g. "14:00") are on day
+func (p timeParser) parseOn(s string, day time.Time) (time.Time, error) {
+	expr := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	loc := p.now.Location()
+
+	if expr == "" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+
+	if expr == "now" {
+		return p.now, nil
+	}
+
+	if t, ok := p.relative(expr); ok {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, strings.ToUpper(expr)); err == nil {
+		return t.In(loc), nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, expr, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	if t, ok := p.date(expr); ok {
+		return t, nil
+	}
+
+	// [date] clock, e.g. "14:00", "yesterday 2pm", "last friday 17:30"
+	words := strings.Split(expr, " ")
+	for i := len(words) - 1; i >= 0; i-- {
+		base := day.In(loc)
+		if i > 0 {
+			var ok bool
+			if base, ok = p.date(strings.Join(words[:i], " ")); !ok {
+				continue
+			}
+		}
+
+		h, m, sec, ok := parseClockExpr(strings.Join(words[i:], " "))
+		if ok {
+			return time.Date(base.Year(), base.Month(), base.Day(), h, m, sec, 0, loc), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("bad time %q (e.g. 14:00, 2pm, -15m, 2h ago, yesterday 9am, 2006-01-02 15:04)", s)
+}
+
+// parseDate parses a date expression to midnight of that day
+func (p timeParser) parseDate(s string) (time.Time, error) {
+	t, err := p.parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad date %q (e.g. today, yesterday, monday, last week, 2006-01-02)", s)
+	}
+
+	return startOfDay(t), nil
+}
+
+// date parses a date expression (e.g. "yesterday", "monday", "last week") to midnight of that day
+func (p timeParser) date(expr string) (time.Time, bool) {
+	today := startOfDay(p.now)
+
+	switch expr {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "this week", "week":
+		return p.startOfWeek(today), true
+	case "last week":
+		return p.startOfWeek(today).AddDate(0, 0, -7), true
+	case "this month", "month":
+		return time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location()), true
+	case "last month":
+		return time.Date(today.Year(), today.Month()-1, 1, 0, 0, 0, 0, today.Location()), true
+	case "this year", "year":
+		return time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location()), true
+	case "last year":
+		return time.Date(today.Year()-1, time.January, 1, 0, 0, 0, 0, today.Location()), true
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", expr, p.now.Location()); err == nil {
+		return t, true
+	}
+
+	// "monday" is the last monday, today included. "last monday" is before today.
+	name, last := strings.CutPrefix(expr, "last ")
+	if wd, ok := weekdays[name]; ok {
+		days := (int(today.Weekday()) - int(wd) + 7) % 7
+		if last && days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, -days), true
+	}
+
+	return time.Time{}, false
+}
+
+// startOfWeek returns the first day of day's week
+func (p timeParser) startOfWeek(day time.Time) time.Time {
+	days := (int(day.Weekday()) - int(p.weekStart) + 7) % 7
+	return startOfDay(day).AddDate(0, 0, -days)
+}
+
+// relative parses relative expressions such as "-15m", "+1h", "2h ago" or "in 3 days"
+func (p timeParser) relative(expr string) (time.Time, bool) {
+	sign := 0
+	switch {
+	case strings.HasPrefix(expr, "-"):
+		sign, expr = -1, expr[1:]
+	case strings.HasPrefix(expr, "+"):
+		sign, expr = 1, expr[1:]
+	case strings.HasPrefix(expr, "in "):
+		sign, expr = 1, expr[3:]
+	}
+
+	if s, ok := strings.CutSuffix(expr, " ago"); ok && sign == 0 {
+		sign, expr = -1, s
+	}
+
+	if sign == 0 {
+		return time.Time{}, false
+	}
+
+	days, dur, ok := parseSpan(expr)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return p.now.AddDate(0, 0, sign*days).Add(time.Duration(sign) * dur), true
+}
+
+// parseSpan parses a time span such as "1h30m", "2 hours", "1.5h" or "3 days"
+func parseSpan(expr string) (int, time.Duration, bool) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return 0, 0, false
+	}
+
+	matches := spanRe.FindAllStringSubmatchIndex(expr, -1)
+	days, dur, end := 0, time.Duration(0), 0
+	for _, m := range matches {
+		// Only spaces between parts
+		if strings.TrimSpace(expr[end:m[0]]) != "" {
+			return 0, 0, false
+		}
+		end = m[1]
+
+		num, unit := expr[m[2]:m[3]], expr[m[4]:m[5]]
+		if n, ok := spanDays[unit]; ok {
+			count, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, 0, false
+			}
+			days += count * n
+			continue
+		}
+
+		size, ok := spanUnits[unit]
+		if !ok {
+			return 0, 0, false
+		}
+
+		value, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		dur += time.Duration(value * float64(size))
+	}
+
+	if len(matches) == 0 || strings.TrimSpace(expr[end:]) != "" {
+		return 0, 0, false
+	}
+
+	return days, dur, true
+}
+
+// parseClockExpr parses time of day such as "14:00", "14:00:30", "2pm" or "2:30 pm"
+func parseClockExpr(expr string) (int, int, int, bool) {
+	switch expr {
+	case "noon":
+		return 12, 0, 0, true
+	case "midnight":
+		return 0, 0, 0, true
+	}
+
+	m := clockRe.FindStringSubmatch(expr)
+	if m == nil {
+		return 0, 0, 0, false
+	}
+
+	// Bare number (e.g. "14") is not a time
+	if m[2] == "" && m[4] == "" {
+		return 0, 0, 0, false
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	sec, _ := strconv.Atoi(m[3])
+
+	switch m[4] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, 0, false
+		}
+		hour %= 12
+		if m[4] == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0, 0, 0, false
+		}
+	}
+
+	if minute > 59 || sec > 59 {
+		return 0, 0, 0, false
+	}
+
+	return hour, minute, sec, true
+}
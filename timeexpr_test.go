@@ -0,0 +1,171 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeParser(t *testing.T) {
+	loc := time.FixedZone("IST", 2*60*60)
+	// Wednesday
+	now := time.Date(2023, 1, 4, 15, 4, 5, 0, loc)
+	p := newTimeParser(now)
+	at := func(month time.Month, day, h, m, s int) time.Time {
+		return time.Date(2023, month, day, h, m, s, 0, loc)
+	}
+
+	cases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"now", now},
+		{"NOW", now},
+
+		// relative
+		{"-15m", now.Add(-15 * time.Minute)},
+		{"+1h", now.Add(time.Hour)},
+		{"15m ago", now.Add(-15 * time.Minute)},
+		{"2h ago", now.Add(-2 * time.Hour)},
+		{"1h30m ago", now.Add(-90 * time.Minute)},
+		{"1.5h ago", now.Add(-90 * time.Minute)},
+		{"2 hours ago", now.Add(-2 * time.Hour)},
+		{"1 hour 15 minutes ago", now.Add(-75 * time.Minute)},
+		{"in 10 min", now.Add(10 * time.Minute)},
+		{"3 days ago", now.AddDate(0, 0, -3)},
+		{"-1w", now.AddDate(0, 0, -7)},
+
+		// days
+		{"today", at(1, 4, 0, 0, 0)},
+		{"yesterday", at(1, 3, 0, 0, 0)},
+		{"tomorrow", at(1, 5, 0, 0, 0)},
+		{"monday", at(1, 2, 0, 0, 0)},
+		{"wed", at(1, 4, 0, 0, 0)},
+		{"last wednesday", at(12, 28, 0, 0, 0).AddDate(-1, 0, 0)},
+		{"thursday", at(12, 29, 0, 0, 0).AddDate(-1, 0, 0)},
+		{"this week", at(1, 2, 0, 0, 0)},
+		{"last week", at(12, 26, 0, 0, 0).AddDate(-1, 0, 0)},
+		{"this month", at(1, 1, 0, 0, 0)},
+		{"last month", at(12, 1, 0, 0, 0).AddDate(-1, 0, 0)},
+		{"last year", at(1, 1, 0, 0, 0).AddDate(-1, 0, 0)},
+		{"2022-12-25", at(12, 25, 0, 0, 0).AddDate(-1, 0, 0)},
+
+		// timestamps
+		{"2023-01-02 09:30", at(1, 2, 9, 30, 0)},
+		{"2023-01-02 09:30:15", at(1, 2, 9, 30, 15)},
+		{"2023-01-02T09:30", at(1, 2, 9, 30, 0)},
+		{"2023-01-02T09:30:00Z", time.Date(2023, 1, 2, 9, 30, 0, 0, time.UTC)},
+		{"2023-01-02T09:30:00+02:00", at(1, 2, 9, 30, 0)},
+
+		// time of day
+		{"14:00", at(1, 4, 14, 0, 0)},
+		{"9:05", at(1, 4, 9, 5, 0)},
+		{"14:00:30", at(1, 4, 14, 0, 30)},
+		{"2pm", at(1, 4, 14, 0, 0)},
+		{"2:30pm", at(1, 4, 14, 30, 0)},
+		{"2:30 PM", at(1, 4, 14, 30, 0)},
+		{"12am", at(1, 4, 0, 0, 0)},
+		{"12pm", at(1, 4, 12, 0, 0)},
+		{"noon", at(1, 4, 12, 0, 0)},
+
+		// day + time
+		{"yesterday 14:00", at(1, 3, 14, 0, 0)},
+		{"monday 9am", at(1, 2, 9, 0, 0)},
+		{"last friday 5:30pm", at(12, 30, 17, 30, 0).AddDate(-1, 0, 0)},
+		{"2023-01-02 3pm", at(1, 2, 15, 0, 0)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			out, err := p.parse(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !out.Equal(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, out)
+			}
+		})
+	}
+}
+
+func TestTimeParserErrors(t *testing.T) {
+	p := newTimeParser(time.Date(2023, 1, 4, 15, 4, 5, 0, time.UTC))
+
+	cases := []string{
+		"",
+		"14",
+		"25:00",
+		"14:60",
+		"13pm",
+		"0am",
+		"15m",
+		"ago",
+		"banana",
+		"01-02-2023",
+		"2023-13-01",
+		"yesterday banana",
+		"5 parsecs ago",
+	}
+
+	for _, expr := range cases {
+		t.Run(expr, func(t *testing.T) {
+			if out, err := p.parse(expr); err == nil {
+				t.Errorf("expected error, got %v", out)
+			}
+		})
+	}
+}
+
+func TestTimeParserOn(t *testing.T) {
+	p := newTimeParser(time.Date(2023, 1, 4, 15, 4, 5, 0, time.UTC))
+	day := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"17:30", time.Date(2023, 1, 2, 17, 30, 0, 0, time.UTC)},
+		{"today 17:30", time.Date(2023, 1, 4, 17, 30, 0, 0, time.UTC)},
+		{"2023-01-03 08:15", time.Date(2023, 1, 3, 8, 15, 0, 0, time.UTC)},
+		{"2023-01-03", time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"-1h", time.Date(2023, 1, 4, 14, 4, 5, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			out, err := p.parseOn(tc.expr, day)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !out.Equal(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, out)
+			}
+		})
+	}
+}
+
+func TestTimeParserDate(t *testing.T) {
+	p := newTimeParser(time.Date(2023, 1, 4, 15, 4, 5, 0, time.UTC))
+
+	out, err := p.parseDate("2h ago")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC)
+	if !out.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, out)
+	}
+
+	p.weekStart = time.Sunday
+	out, err = p.parseDate("this week")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !out.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, out)
+	}
+}